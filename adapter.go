@@ -0,0 +1,92 @@
+package easygin
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Adapter binds the parameters of a handler and returns the function calling it,
+// the adapters generated by `easygin gen` bind the parameters without reflection
+type Adapter func(ctx *gin.Context) (call func() *Response, err error)
+
+var adapters sync.Map // code pointer of handler --> Adapter
+
+// closures and method values share the code pointer, etc.: pkg.F.func1, pkg.T.M-fm
+var closureName = regexp.MustCompile(`\.func\d+(\.\d+)*$|-fm$`)
+
+// RegisterAdapter register the adapter of handler, it is called in the init function of the generated code.
+// routes registered with handler use the adapter instead of reflection, handler must be a package level function
+func RegisterAdapter(handler Handler, adapter Adapter) {
+	fv := reflect.ValueOf(handler)
+	if fv.Kind() != reflect.Func {
+		panic("handler must be func type")
+	}
+	if f := runtime.FuncForPC(fv.Pointer()); f == nil || closureName.MatchString(f.Name()) {
+		panic("handler of adapter must be a package level function")
+	}
+	adapters.Store(fv.Pointer(), adapter)
+}
+
+func lookupAdapter(fv reflect.Value) Adapter {
+	if adapter, ok := adapters.Load(fv.Pointer()); ok {
+		return adapter.(Adapter)
+	}
+	return nil
+}
+
+// BindStruct bind the struct pointed by ptr in the same way as the handler parameters:
+// fields with uri or header tags are mapped from path params and headers,
+// then the struct is bound from query if the request has no body, otherwise from body.
+// ptr is stored in ctx and returned by BoundRequest. principal types registered by RegisterPrincipal
+// are set to the principal of Auth instead, the request is rejected if it is not authenticated
+func BindStruct(ctx *gin.Context, ptr interface{}) error {
+	if t := reflect.TypeOf(ptr); t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		err := fmt.Errorf("BindStruct needs a pointer to struct, got %T", ptr)
+		_ = ctx.AbortWithError(http.StatusInternalServerError, err)
+		return err
+	}
+	// principals are injected from the authentication instead of binding
+	if isPrincipalType(reflect.TypeOf(ptr).Elem()) {
+		return injectPrincipal(ctx, ptr)
+	}
+	ctx.Set(boundRequestKey, ptr)
+
+	// map fields with uri or header tags first without validation,
+	// the following binding validates the whole struct
+	if err := mapURIAndHeader(ctx, structTagsOf(reflect.TypeOf(ptr).Elem()), ptr); err != nil {
+		_ = ctx.AbortWithError(http.StatusBadRequest, err).SetType(gin.ErrorTypeBind)
+		return err
+	}
+
+	// bind from url
+	if ctx.Request.ContentLength == 0 {
+		return ctx.BindQuery(ptr)
+	}
+	// bind json from body
+	if ctx.ContentType() == ContentTypeJson {
+		return ctx.BindJSON(ptr)
+	}
+	return ctx.Bind(ptr)
+}
+
+// Query reads the values of the query in the order of the keys,
+// it binds the int, uint and string parameters in the generated adapters
+type Query struct {
+	ctx  *gin.Context
+	vals queryValues
+}
+
+func NewQuery(ctx *gin.Context) *Query {
+	return &Query{ctx: ctx}
+}
+
+// Next return the first value of the next key in the query
+func (q *Query) Next() (string, error) {
+	return q.vals.next(q.ctx)
+}
@@ -0,0 +1,104 @@
+package easygin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func adaptedUser(ctx *gin.Context, id int, user *User) *Response {
+	user.Id = id
+	return OkData(user)
+}
+
+func TestAdapter(t *testing.T) {
+	called := 0
+	RegisterAdapter(adaptedUser, func(ctx *gin.Context) (func() *Response, error) {
+		called++
+		query := NewQuery(ctx)
+		s1, err := query.Next()
+		if err != nil {
+			return nil, err
+		}
+		n1, err := strconv.ParseInt(s1, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		p1 := int(n1)
+		p2 := new(User)
+		if err := BindStruct(ctx, p2); err != nil {
+			return nil, err
+		}
+		return func() *Response {
+			return adaptedUser(ctx, p1, p2)
+		}, nil
+	})
+
+	server := New()
+	server.GET("/generated", adaptedUser)
+	server.GET("/reflect", func(ctx *gin.Context, id int, user *User) *Response {
+		user.Id = id
+		return OkData(user)
+	})
+
+	for _, query := range []string{"?id=3&username=ape", "?id=a&username=ape", ""} {
+		t.Run(query, func(t *testing.T) {
+			var bodies [2]string
+			for i, path := range []string{"/generated", "/reflect"} {
+				w := serveRequest(server, http.MethodGet, path+query, nil)
+				bodies[i] = fmt.Sprintf("%d %s", w.Code, w.Body.String())
+			}
+			if bodies[0] != bodies[1] {
+				t.Errorf("generated adapter responds %s, reflection responds %s", bodies[0], bodies[1])
+			}
+		})
+	}
+	if called != 3 {
+		t.Errorf("expect the adapter to be called 3 times, got %d", called)
+	}
+}
+
+type userController struct{}
+
+func (userController) Get() *Response {
+	return Ok()
+}
+
+func TestRegisterAdapterClosure(t *testing.T) {
+	adapter := func(ctx *gin.Context) (func() *Response, error) {
+		return nil, nil
+	}
+	for name, handler := range map[string]Handler{
+		"closure":      func() *Response { return Ok() },
+		"method value": userController{}.Get,
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expect %s to be rejected", name)
+				}
+			}()
+			RegisterAdapter(handler, adapter)
+		})
+	}
+}
+
+func TestBindStructNotStruct(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/?d=1", nil)
+	var d time.Duration
+	for _, ptr := range []interface{}{&d, User{}, nil} {
+		if err := BindStruct(ctx, ptr); err == nil {
+			t.Errorf("expect the error of %T", ptr)
+		}
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expect 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,234 @@
+package easygin
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// principalKey is the key of the principal in gin.Context
+const principalKey = "easygin/principal"
+
+const UnauthorizedCode = 401
+
+var RespUnauthorized = RespError(&RespErrorImpl{
+	Codee:    UnauthorizedCode,
+	Messagee: "unauthorized",
+})
+
+// ErrNoCredentials is returned by the authenticators if the request has no credentials of them,
+// the next authenticator is tried
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator authenticates the request and returns the principal, etc.: *Claims.
+// a RespError returned is used as the envelope of the rejection, otherwise RespUnauthorized is used
+type Authenticator interface {
+	Authenticate(ctx *gin.Context) (principal interface{}, err error)
+}
+
+// challenger is implemented by the authenticators which send the WWW-Authenticate header
+type challenger interface {
+	challenge() string
+}
+
+// Auth authenticate the requests of the route with the authenticators in order,
+// the principal is injected into the handler parameters of its type, see RegisterPrincipal
+func Auth(authenticators ...Authenticator) RouteOption {
+	return routeOptionFunc(func(rt *route) {
+		rt.guards = append(rt.guards, authGuard(authenticators))
+	})
+}
+
+// Auth authenticate the requests of the routes registered in the group after it, see Auth
+func (r *RouterGroup) Auth(authenticators ...Authenticator) {
+	r.guards = append(r.guards, authGuard(authenticators))
+}
+
+// Auth authenticate the requests of the routes registered after it, see Auth
+func (e *EasyGin) Auth(authenticators ...Authenticator) {
+	e.root.Auth(authenticators...)
+}
+
+func authGuard(authenticators []Authenticator) guard {
+	return guard{authentication: true, check: func(ctx *gin.Context) *Response {
+		err := ErrNoCredentials
+		for _, authenticator := range authenticators {
+			principal, e := authenticator.Authenticate(ctx)
+			if e == nil {
+				ctx.Set(principalKey, principal)
+				return nil
+			}
+			if !errors.Is(e, ErrNoCredentials) {
+				err = e
+				break
+			}
+		}
+
+		for _, authenticator := range authenticators {
+			if c, ok := authenticator.(challenger); ok {
+				ctx.Writer.Header().Add("WWW-Authenticate", c.challenge())
+			}
+		}
+		if respErr := AsRespError(err); respErr != nil {
+			return FailStatus(http.StatusUnauthorized, respErr)
+		}
+		return FailStatus(http.StatusUnauthorized, RespUnauthorized)
+	}}
+}
+
+// Principal return the principal of the request authenticated by Auth, nil if not authenticated
+func Principal(ctx *gin.Context) interface{} {
+	principal, _ := ctx.Get(principalKey)
+	return principal
+}
+
+var principalTypes sync.Map // reflect.Type --> bool
+
+// RegisterPrincipal register T as a principal type, the handler parameters of T or *T are injected with the principal
+// instead of binding from the request. the handlers with them must be on the routes authenticated by Auth, otherwise
+// the registration panics. the principals of the authenticators in this package are registered,
+// custom authenticators need to register their principals
+func RegisterPrincipal[T any]() {
+	principalTypes.Store(reflect.TypeOf((*T)(nil)).Elem(), true)
+}
+
+func isPrincipalType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	_, ok := principalTypes.Load(t)
+	return ok
+}
+
+// mustNotUsePrincipal panic if the handler has principal parameters, it checks the handlers not authenticated by Auth,
+// so the principals are never bound from the client input
+func mustNotUsePrincipal(ft reflect.Type) {
+	for i := 0; i < ft.NumIn(); i++ {
+		if isPrincipalType(ft.In(i)) {
+			panic(fmt.Sprintf("principal parameter %s needs the route authenticated by Auth", ft.In(i)))
+		}
+	}
+}
+
+// injectPrincipal set the principal to the struct pointed by ptr, the request is rejected if there is no principal
+func injectPrincipal(ctx *gin.Context, ptr interface{}) error {
+	dst := reflect.ValueOf(ptr).Elem()
+	pv := reflect.ValueOf(Principal(ctx))
+	switch {
+	case pv.IsValid() && pv.Type() == dst.Type():
+		dst.Set(pv)
+		return nil
+	case pv.IsValid() && pv.Kind() == reflect.Pointer && pv.Type().Elem() == dst.Type() && !pv.IsNil():
+		dst.Set(pv.Elem())
+		return nil
+	}
+
+	resp := FailStatus(http.StatusUnauthorized, RespUnauthorized)
+	ctx.AbortWithStatusJSON(resp.Status, &resp.R)
+	pool.Put(resp)
+	return ErrNoCredentials
+}
+
+// BasicPrincipal is the principal of Basic
+type BasicPrincipal struct {
+	Username string `json:"username"`
+}
+
+type BasicConfig struct {
+	Realm string
+	// Users is the passwords of the users
+	Users map[string]string
+	// Validate validates the users not in Users, optional
+	Validate func(username, password string) bool
+}
+
+type basicAuthenticator struct {
+	config BasicConfig
+}
+
+// Basic authenticate the requests by HTTP Basic authentication, the principal is *BasicPrincipal
+func Basic(config BasicConfig) Authenticator {
+	if config.Realm == "" {
+		config.Realm = "Authorization Required"
+	}
+	return &basicAuthenticator{config: config}
+}
+
+func (a *basicAuthenticator) Authenticate(ctx *gin.Context) (interface{}, error) {
+	username, password, ok := ctx.Request.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	if expect, ok := a.config.Users[username]; ok {
+		if subtle.ConstantTimeCompare([]byte(expect), []byte(password)) == 1 {
+			return &BasicPrincipal{Username: username}, nil
+		}
+	} else if a.config.Validate != nil && a.config.Validate(username, password) {
+		return &BasicPrincipal{Username: username}, nil
+	}
+	return nil, errors.New("invalid username or password")
+}
+
+func (a *basicAuthenticator) challenge() string {
+	return "Basic realm=" + strconv.Quote(a.config.Realm)
+}
+
+// APIKeyPrincipal is the principal of APIKey
+type APIKeyPrincipal struct {
+	// Name is the name of the client the key belongs to
+	Name string `json:"name"`
+}
+
+type APIKeyConfig struct {
+	// Header is the header of the key, default is X-API-Key
+	Header string
+	// Query is the query parameter of the key, optional
+	Query string
+	// Keys is the names of the clients by their keys
+	Keys map[string]string
+}
+
+type apiKeyAuthenticator struct {
+	config APIKeyConfig
+}
+
+// APIKey authenticate the requests by the static keys, the principal is *APIKeyPrincipal
+func APIKey(config APIKeyConfig) Authenticator {
+	if config.Header == "" {
+		config.Header = "X-API-Key"
+	}
+	return &apiKeyAuthenticator{config: config}
+}
+
+func (a *apiKeyAuthenticator) Authenticate(ctx *gin.Context) (interface{}, error) {
+	key := ctx.GetHeader(a.config.Header)
+	if key == "" && a.config.Query != "" {
+		key = ctx.Query(a.config.Query)
+	}
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	// compare all the keys in constant time
+	name, found := "", false
+	for k, n := range a.config.Keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			name, found = n, true
+		}
+	}
+	if !found {
+		return nil, errors.New("invalid api key")
+	}
+	return &APIKeyPrincipal{Name: name}, nil
+}
+
+func init() {
+	RegisterPrincipal[BasicPrincipal]()
+	RegisterPrincipal[APIKeyPrincipal]()
+}
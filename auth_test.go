@@ -0,0 +1,184 @@
+package easygin
+
+import (
+	"crypto"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signJWT sign the claims with HS256 by secret, or RS256 by key
+func signJWT(t *testing.T, kid string, claims interface{}, secret []byte, key *rsa.PrivateKey) string {
+	alg := "HS256"
+	if key != nil {
+		alg = "RS256"
+	}
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	if key != nil {
+		digest := sha256.Sum256([]byte(input))
+		var err error
+		if signature, err = rsa.SignPKCS1v15(crand.Reader, key, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestAuth(t *testing.T) {
+	type Claims struct {
+		Subject string `json:"sub"`
+		Name    string `json:"name"`
+	}
+	type Req struct {
+		ID int `form:"id"`
+	}
+
+	key, err := rsa.GenerateKey(crand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "rsa",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	if err = os.WriteFile(jwksFile, jwks, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("secret")
+	jwtAuth, err := JWT[Claims](JWTConfig{Secret: secret, JWKSFile: jwksFile, Issuer: "easygin", Leeway: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := New()
+	group := server.Group("/api")
+	group.Auth(jwtAuth)
+	group.GET("/user", func(ctx *gin.Context, user *Claims, req Req) *Response {
+		if *user != *Principal(ctx).(*Claims) {
+			t.Error("expect the principal of the context")
+		}
+		return OkData(user.Name + ":" + strconv.Itoa(req.ID))
+	})
+	server.GET("/client", Auth(APIKey(APIKeyConfig{Query: "key", Keys: map[string]string{"k1": "client1"}}),
+		Basic(BasicConfig{Realm: "easygin", Users: map[string]string{"admin": "pass"}})),
+		func(client *APIKeyPrincipal, user *BasicPrincipal) *Response {
+			return Ok()
+		})
+	server.GET("/key", Auth(APIKey(APIKeyConfig{Keys: map[string]string{"k1": "client1"}})), func(client APIKeyPrincipal) *Response {
+		return OkData(client.Name)
+	})
+	server.GET("/basic", Auth(Basic(BasicConfig{Validate: func(username, password string) bool {
+		return username == password
+	}})), func(user *BasicPrincipal) *Response {
+		return OkData(user.Username)
+	})
+
+	unauthorized := `{"data":null,"code":401,"message":"unauthorized"}`
+	exp := time.Now().Add(time.Hour).Unix()
+	bearer := func(kid string, claims map[string]interface{}, secret []byte, key *rsa.PrivateKey) []string {
+		return []string{"Authorization", "Bearer " + signJWT(t, kid, claims, secret, key)}
+	}
+	basic := func(userinfo string) []string {
+		return []string{"Authorization", "Basic " + base64.StdEncoding.EncodeToString([]byte(userinfo))}
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		header []string
+		status int
+		body   string
+	}{
+		{"hs256", "/api/user?id=1", bearer("", map[string]interface{}{"sub": "1", "name": "hs", "iss": "easygin", "exp": exp}, secret, nil), 200, `{"data":"hs:1","code":0,"message":"success"}`},
+		{"rs256", "/api/user?id=2", []string{"Authorization", "bearer " + signJWT(t, "rsa", map[string]interface{}{"sub": "2", "name": "rs", "iss": "easygin"}, nil, key)}, 200, `{"data":"rs:2","code":0,"message":"success"}`},
+		{"no token", "/api/user", nil, 401, unauthorized},
+		{"wrong secret", "/api/user", bearer("", map[string]interface{}{"iss": "easygin"}, []byte("wrong"), nil), 401, unauthorized},
+		{"wrong issuer", "/api/user", bearer("", map[string]interface{}{"iss": "other"}, secret, nil), 401, unauthorized},
+		{"expired", "/api/user", bearer("", map[string]interface{}{"iss": "easygin", "exp": time.Now().Add(-time.Minute).Unix()}, secret, nil), 401, `{"data":null,"code":401,"message":"token expired"}`},
+		// the rsa key can not verify hs tokens
+		{"algorithm confusion", "/api/user", bearer("rsa", map[string]interface{}{"iss": "easygin"}, x509.MarshalPKCS1PublicKey(&key.PublicKey), nil), 401, unauthorized},
+		{"api key", "/key", []string{"X-API-Key", "k1"}, 200, `{"data":"client1","code":0,"message":"success"}`},
+		{"wrong api key", "/key", []string{"X-API-Key", "k2"}, 401, unauthorized},
+		{"basic", "/basic", basic("user:user"), 200, `{"data":"user","code":0,"message":"success"}`},
+		{"wrong password", "/basic", basic("user:pass"), 401, unauthorized},
+		{"all authenticators", "/client?key=k1", nil, 401, unauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serveRequest(server, http.MethodGet, test.path, nil, test.header...)
+			if w.Code != test.status || w.Body.String() != test.body {
+				t.Errorf("expect %d %s, got %d %s", test.status, test.body, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("challenge", func(t *testing.T) {
+		if w := serveRequest(server, http.MethodGet, "/client", nil); w.Header().Values("WWW-Authenticate")[0] != `Basic realm="easygin"` {
+			t.Errorf("unexpected WWW-Authenticate %v", w.Header().Values("WWW-Authenticate"))
+		}
+		if w := serveRequest(server, http.MethodGet, "/api/user", nil); w.Header().Get("WWW-Authenticate") != "Bearer" {
+			t.Errorf("unexpected WWW-Authenticate %v", w.Header().Values("WWW-Authenticate"))
+		}
+	})
+
+	for _, info := range server.RegisteredRoutes() {
+		if info.Path == "/api/user" && (info.Params[1].Sources[0] != SourcePrincipal || info.Params[2].Sources[0] != SourceQuery) {
+			t.Errorf("unexpected params %+v", info.Params)
+		}
+	}
+
+	// the principals are never bound from the client input on the routes without Auth
+	t.Run("without auth", func(t *testing.T) {
+		for name, register := range map[string]func(){
+			"route": func() {
+				server.GET("/public", func(user *Claims) *Response { return OkData(user.Name) })
+			},
+			"middleware": func() {
+				server.GET("/middleware", func(user BasicPrincipal) *Response { return nil },
+					Auth(jwtAuth), func() *Response { return Ok() })
+			},
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s: expect the registration to panic", name)
+					}
+				}()
+				register()
+			}()
+		}
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/public?Name=admin", nil)
+		var user Claims
+		if err := BindStruct(ctx, &user); err == nil || w.Code != 401 || user.Name != "" {
+			t.Errorf("expect 401, got %d %v %+v", w.Code, err, user)
+		}
+	})
+}
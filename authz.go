@@ -0,0 +1,198 @@
+package easygin
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ForbiddenCode = 403
+
+var RespForbidden = RespError(&RespErrorImpl{
+	Codee:    ForbiddenCode,
+	Messagee: "forbidden",
+})
+
+// Policy decides whether the principal authenticated by Auth can access the route,
+// req is the bound request returned by BoundRequest. it returns nil to allow the request,
+// a RespError returned is used as the envelope of the denial, otherwise RespForbidden is used.
+// the policies are described in the route introspection by their String method if they implement fmt.Stringer
+type Policy interface {
+	Authorize(ctx *gin.Context, principal interface{}, req interface{}) error
+}
+
+// PolicyFunc is a Policy of a function
+type PolicyFunc func(ctx *gin.Context, principal interface{}, req interface{}) error
+
+func (f PolicyFunc) Authorize(ctx *gin.Context, principal interface{}, req interface{}) error {
+	return f(ctx, principal, req)
+}
+
+// beforeBinder is implemented by the policies which do not use the request, they are checked before binding
+type beforeBinder interface {
+	beforeBind()
+}
+
+// RoleHolder is implemented by the principals having roles, which are checked by RequireRoles
+type RoleHolder interface {
+	Roles() []string
+}
+
+// ScopeHolder is implemented by the principals having scopes, which are checked by RequireScopes
+type ScopeHolder interface {
+	Scopes() []string
+}
+
+// Authorize authorize the requests of the route by the policies in order,
+// it is checked after the authorization of the group and Auth
+func Authorize(policies ...Policy) RouteOption {
+	return routeOptionFunc(func(rt *route) {
+		rt.guards = append(rt.guards, authorizeGuards(policies)...)
+	})
+}
+
+// Authorize authorize the requests of the routes registered in the group after it, see Authorize
+func (r *RouterGroup) Authorize(policies ...Policy) {
+	r.guards = append(r.guards, authorizeGuards(policies)...)
+}
+
+// Authorize authorize the requests of the routes registered after it, see Authorize
+func (e *EasyGin) Authorize(policies ...Policy) {
+	e.root.Authorize(policies...)
+}
+
+func authorizeGuards(policies []Policy) []guard {
+	guards := make([]guard, 0, len(policies))
+	for _, policy := range policies {
+		policy := policy
+		_, before := policy.(beforeBinder)
+		guards = append(guards, guard{
+			afterBind:     !before,
+			authorization: describePolicy(policy),
+			check: func(ctx *gin.Context) *Response {
+				principal := Principal(ctx)
+				if principal == nil {
+					return FailStatus(http.StatusUnauthorized, RespUnauthorized)
+				}
+				err := policy.Authorize(ctx, principal, BoundRequest(ctx))
+				if err == nil {
+					return nil
+				}
+				if respErr := AsRespError(err); respErr != nil {
+					return FailStatus(http.StatusForbidden, respErr)
+				}
+				return FailStatus(http.StatusForbidden, RespForbidden)
+			},
+		})
+	}
+	return guards
+}
+
+func describePolicy(policy Policy) string {
+	if s, ok := policy.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", policy)
+}
+
+type rolesPolicy []string
+
+// RequireRoles allow the principals having any of the roles, the principal must implement RoleHolder
+func RequireRoles(roles ...string) Policy {
+	return rolesPolicy(roles)
+}
+
+func (p rolesPolicy) Authorize(ctx *gin.Context, principal interface{}, req interface{}) error {
+	if holder, ok := principal.(RoleHolder); ok {
+		for _, role := range holder.Roles() {
+			for _, r := range p {
+				if role == r {
+					return nil
+				}
+			}
+		}
+	}
+	return RespForbidden
+}
+
+func (p rolesPolicy) beforeBind() {}
+
+func (p rolesPolicy) String() string {
+	return "roles(" + strings.Join(p, "|") + ")"
+}
+
+type scopesPolicy []string
+
+// RequireScopes allow the principals having all the scopes, the principal must implement ScopeHolder
+func RequireScopes(scopes ...string) Policy {
+	return scopesPolicy(scopes)
+}
+
+func (p scopesPolicy) Authorize(ctx *gin.Context, principal interface{}, req interface{}) error {
+	holder, ok := principal.(ScopeHolder)
+	if !ok {
+		return RespForbidden
+	}
+	has := make(map[string]bool)
+	for _, scope := range holder.Scopes() {
+		has[scope] = true
+	}
+	for _, scope := range p {
+		if !has[scope] {
+			return RespForbidden
+		}
+	}
+	return nil
+}
+
+func (p scopesPolicy) beforeBind() {}
+
+func (p scopesPolicy) String() string {
+	return "scopes(" + strings.Join(p, ",") + ")"
+}
+
+type attrPolicy[P, R any] struct {
+	name  string
+	check func(principal P, req R) bool
+}
+
+// RequireAttr allow the requests for which check returns true, check is called with the principal
+// and the bound request, etc.: the owner id of the request equals the id of the principal.
+// P and R are the types of the principal and the request parameter of the handler, such as *Claims and *Req,
+// the request is denied if they do not match. name describes the check in the route introspection
+func RequireAttr[P, R any](name string, check func(principal P, req R) bool) Policy {
+	return &attrPolicy[P, R]{name: name, check: check}
+}
+
+func (p *attrPolicy[P, R]) Authorize(ctx *gin.Context, principal interface{}, req interface{}) error {
+	pv, ok := valueAs[P](principal)
+	if !ok {
+		return RespForbidden
+	}
+	rv, ok := valueAs[R](req)
+	if !ok || !p.check(pv, rv) {
+		return RespForbidden
+	}
+	return nil
+}
+
+func (p *attrPolicy[P, R]) String() string {
+	return "attr(" + p.name + ")"
+}
+
+// valueAs convert v to T, v can also be a pointer to T
+func valueAs[T any](v interface{}) (T, bool) {
+	if t, ok := v.(T); ok {
+		return t, true
+	}
+	var zero T
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return zero, false
+	}
+	t, ok := rv.Elem().Interface().(T)
+	return t, ok
+}
@@ -0,0 +1,103 @@
+package easygin
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type authzClaims struct {
+	ID        int      `json:"id"`
+	RoleNames []string `json:"roles"`
+	Scope     string   `json:"scope"`
+}
+
+func (c *authzClaims) Roles() []string {
+	return c.RoleNames
+}
+
+func (c *authzClaims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+func TestAuthorize(t *testing.T) {
+	type Order struct {
+		OwnerID int `form:"owner_id"`
+	}
+
+	secret := []byte("secret")
+	jwtAuth, err := JWT[authzClaims](JWTConfig{Secret: secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	respReadOnly := NewError(4031, "read only")
+
+	server := New()
+	group := server.Group("/api")
+	group.Auth(jwtAuth)
+	group.Authorize(RequireRoles("user", "admin"))
+	group.GET("/orders", Authorize(RequireScopes("orders:read"),
+		RequireAttr("owner", func(user *authzClaims, order Order) bool {
+			return user.ID == order.OwnerID
+		})), func(order *Order) *Response {
+		return OkData(order.OwnerID)
+	})
+	group.POST("/orders", Authorize(PolicyFunc(func(ctx *gin.Context, principal interface{}, req interface{}) error {
+		if !strings.Contains(principal.(*authzClaims).Scope, "orders:write") {
+			return respReadOnly
+		}
+		return nil
+	})), func(order *Order) *Response {
+		return Ok()
+	})
+	server.GET("/admin", Authorize(RequireRoles("admin")), func() *Response {
+		return Ok()
+	})
+	// the authorization of the group is checked after the authentication of the route
+	admin := server.Group("/admin")
+	admin.Authorize(RequireRoles("admin"))
+	admin.GET("/users", Auth(jwtAuth), func() *Response {
+		return Ok()
+	})
+
+	token := func(id int, role, scope string) string {
+		return "Bearer " + signJWT(t, "", map[string]interface{}{"id": id, "roles": []string{role}, "scope": scope}, secret, nil)
+	}
+	forbidden := `{"data":null,"code":403,"message":"forbidden"}`
+	tests := []struct {
+		name                string
+		method, path, token string
+		status              int
+		body                string
+	}{
+		{"allowed", http.MethodGet, "/api/orders?owner_id=1", token(1, "user", "orders:read"), 200, `{"data":1,"code":0,"message":"success"}`},
+		{"attribute", http.MethodGet, "/api/orders?owner_id=2", token(1, "user", "orders:read"), 403, forbidden},
+		{"scope", http.MethodGet, "/api/orders?owner_id=1", token(1, "user", "orders:write"), 403, forbidden},
+		{"role", http.MethodGet, "/api/orders?owner_id=1", token(1, "guest", "orders:read"), 403, forbidden},
+		{"policy", http.MethodPost, "/api/orders", token(1, "admin", "orders:read"), 403, `{"data":null,"code":4031,"message":"read only"}`},
+		{"policy allowed", http.MethodPost, "/api/orders", token(1, "admin", "orders:read orders:write"), 200, `{"data":null,"code":0,"message":"success"}`},
+		// the roles are checked without authentication
+		{"unauthenticated", http.MethodGet, "/admin", token(1, "admin", ""), 401, `{"data":null,"code":401,"message":"unauthorized"}`},
+		{"route authentication", http.MethodGet, "/admin/users", token(1, "admin", ""), 200, `{"data":null,"code":0,"message":"success"}`},
+		{"route authentication denied", http.MethodGet, "/admin/users", token(1, "user", ""), 403, forbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serveRequest(server, test.method, test.path, nil, "Authorization", test.token)
+			if w.Code != test.status || w.Body.String() != test.body {
+				t.Errorf("expect %d %s, got %d %s", test.status, test.body, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	for _, info := range server.RegisteredRoutes() {
+		if info.Method == http.MethodGet && info.Path == "/api/orders" {
+			if expect := []string{"roles(user|admin)", "scopes(orders:read)", "attr(owner)"}; fmt.Sprint(info.Authorization) != fmt.Sprint(expect) {
+				t.Errorf("expect authorization %v, got %v", expect, info.Authorization)
+			}
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mangohow/easygin"
+)
+
+func runClient(args []string) error {
+	fs := flag.NewFlagSet("client", flag.ContinueOnError)
+	spec := fs.String("spec", "", "OpenAPI document generated by easygin, file path or http url")
+	pkg := fs.String("pkg", "client", "package name of the generated client")
+	out := fs.String("o", "", "output file, default is stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *spec == "" {
+		return errors.New("-spec is required")
+	}
+
+	data, err := readSpec(*spec)
+	if err != nil {
+		return err
+	}
+	doc := &easygin.OpenAPIDocument{}
+	if err = json.Unmarshal(data, doc); err != nil {
+		return fmt.Errorf("parse %s: %w", *spec, err)
+	}
+
+	src, err := generateClient(doc, *pkg)
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, src)
+}
+
+func readSpec(spec string) ([]byte, error) {
+	if !strings.HasPrefix(spec, "http://") && !strings.HasPrefix(spec, "https://") {
+		return os.ReadFile(spec)
+	}
+
+	resp, err := http.Get(spec)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: %s", spec, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+const schemaRefPrefix = "#/components/schemas/"
+
+type clientGenerator struct {
+	buf bytes.Buffer
+	// types is the go names of the component schemas
+	types    map[string]string
+	usesTime bool
+}
+
+// generateClient generate the source of a typed client of the operations in doc
+func generateClient(doc *easygin.OpenAPIDocument, pkg string) ([]byte, error) {
+	g := &clientGenerator{types: make(map[string]string)}
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// the schemas colliding with the identifiers of the runtime and the operations, or with each other, are renamed
+	used := reservedNames(doc)
+	for _, name := range names {
+		goName := exportName(name)
+		for i := 2; used[goName]; i++ {
+			goName = exportName(name) + strconv.Itoa(i)
+		}
+		used[goName] = true
+		g.types[name] = goName
+	}
+	for _, name := range names {
+		g.printf("type %s %s\n\n", g.types[name], g.goType(doc.Components.Schemas[name]))
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		methods := make([]string, 0, len(doc.Paths[p]))
+		for m := range doc.Paths[p] {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+		for _, m := range methods {
+			g.operation(strings.ToUpper(m), p, doc.Paths[p][m])
+		}
+	}
+
+	imports := []string{"bytes", "context", "encoding/json", "fmt", "net/http", "net/url", "reflect", "strings"}
+	if g.usesTime {
+		imports = append(imports, "time")
+	}
+	var file bytes.Buffer
+	fmt.Fprintf(&file, "// Code generated by easygin client. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	for _, imp := range imports {
+		fmt.Fprintf(&file, "%q\n", imp)
+	}
+	fmt.Fprintf(&file, "\n%q\n)\n\n%s", "github.com/mangohow/easygin", clientRuntime)
+	file.Write(g.buf.Bytes())
+
+	src, err := format.Source(file.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated client: %w\n%s", err, file.String())
+	}
+	return src, nil
+}
+
+// reservedNames return the package level identifiers of the client other than the schemas:
+// the identifiers of clientRuntime and the <Method>Params structs of the operations
+func reservedNames(doc *easygin.OpenAPIDocument) map[string]bool {
+	used := map[string]bool{"Client": true, "New": true, "envelope": true, "addParam": true}
+	for _, ops := range doc.Paths {
+		for _, op := range ops {
+			for _, p := range op.Parameters {
+				if p.In != "path" {
+					used[exportName(op.OperationID)+"Params"] = true
+					break
+				}
+			}
+		}
+	}
+	return used
+}
+
+func (g *clientGenerator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// operation generate the method of the operation, path parameters are passed as arguments,
+// query and header parameters are passed by a <Method>Params struct, and the body by a pointer
+func (g *clientGenerator) operation(method, path string, op *easygin.OpenAPIOperation) {
+	name := exportName(op.OperationID)
+
+	var pathParams, otherParams []*easygin.OpenAPIParameter
+	for _, p := range op.Parameters {
+		if p.In == "path" {
+			pathParams = append(pathParams, p)
+		} else {
+			otherParams = append(otherParams, p)
+		}
+	}
+
+	if len(otherParams) > 0 {
+		g.printf("// %sParams is the query and header parameters of %s\n", name, name)
+		g.printf("type %sParams struct {\n", name)
+		for _, p := range otherParams {
+			g.printf("%s %s\n", exportName(p.Name), g.goType(p.Schema))
+		}
+		g.printf("}\n\n")
+	}
+
+	args := []string{"ctx context.Context"}
+	for _, p := range pathParams {
+		args = append(args, fmt.Sprintf("%s %s", paramName(p.Name), g.goType(p.Schema)))
+	}
+	if len(otherParams) > 0 {
+		args = append(args, fmt.Sprintf("params *%sParams", name))
+	}
+	body := "nil"
+	if op.RequestBody != nil {
+		if mt := op.RequestBody.Content[easygin.ContentTypeJson]; mt != nil {
+			args = append(args, fmt.Sprintf("body *%s", g.goType(mt.Schema)))
+			body = "body"
+		}
+	}
+
+	result := "json.RawMessage"
+	if resp := op.Responses["200"]; resp != nil {
+		if mt := resp.Content[easygin.ContentTypeJson]; mt != nil && mt.Schema != nil {
+			if data := mt.Schema.Properties["data"]; data != nil && (data.Ref != "" || data.Type != "") {
+				result = "*" + g.goType(data)
+			}
+		}
+	}
+
+	summary := op.Summary
+	if summary == "" {
+		summary = method + " " + path
+	}
+	g.printf("// %s %s\n", name, summary)
+	g.printf("func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), result)
+
+	urlPath := fmt.Sprintf("%q", path)
+	for _, p := range pathParams {
+		urlPath = fmt.Sprintf("strings.Replace(%s, %q, url.PathEscape(fmt.Sprint(%s)), 1)", urlPath, "{"+p.Name+"}", paramName(p.Name))
+	}
+	g.printf("path := %s\n", urlPath)
+	g.printf("query := url.Values{}\nheader := http.Header{}\n")
+	if len(otherParams) > 0 {
+		g.printf("if params != nil {\n")
+		for _, p := range otherParams {
+			if p.In == "header" {
+				g.printf("addParam(header, %q, params.%s, %t)\n", http.CanonicalHeaderKey(p.Name), exportName(p.Name), p.Required)
+			} else {
+				g.printf("addParam(query, %q, params.%s, %t)\n", p.Name, exportName(p.Name), p.Required)
+			}
+		}
+		g.printf("}\n")
+	}
+	if result == "json.RawMessage" {
+		g.printf("var out json.RawMessage\n")
+		g.printf("if err := c.do(ctx, %q, path, query, header, %s, &out); err != nil {\nreturn nil, err\n}\n", method, body)
+	} else {
+		g.printf("out := new(%s)\n", strings.TrimPrefix(result, "*"))
+		g.printf("if err := c.do(ctx, %q, path, query, header, %s, out); err != nil {\nreturn nil, err\n}\n", method, body)
+	}
+	g.printf("return out, nil\n}\n\n")
+}
+
+// goType return the go type of the schema
+func (g *clientGenerator) goType(s *easygin.Schema) string {
+	if s == nil {
+		return "interface{}"
+	}
+	if s.Ref != "" {
+		return g.types[strings.TrimPrefix(s.Ref, schemaRefPrefix)]
+	}
+
+	switch s.Type {
+	case "boolean":
+		return "bool"
+	case "integer":
+		switch {
+		case s.Format == "int32":
+			return "int32"
+		case s.Format == "int64":
+			return "int64"
+		case s.Minimum != nil && *s.Minimum >= 0:
+			return "uint64"
+		}
+		return "int"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "string":
+		switch s.Format {
+		case "date-time":
+			g.usesTime = true
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		return "string"
+	case "array":
+		return "[]" + g.goType(s.Items)
+	case "object":
+		if s.Properties == nil {
+			return "map[string]" + g.goType(s.AdditionalProperties)
+		}
+		return g.structType(s)
+	}
+	return "interface{}"
+}
+
+func (g *clientGenerator) structType(s *easygin.Schema) string {
+	required := make(map[string]bool, len(s.Required))
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	props := make([]string, 0, len(s.Properties))
+	for p := range s.Properties {
+		props = append(props, p)
+	}
+	sort.Strings(props)
+
+	var sb strings.Builder
+	sb.WriteString("struct {\n")
+	for _, p := range props {
+		prop := s.Properties[p]
+		typ := g.goType(prop)
+		tag := p
+		if !required[p] {
+			tag += ",omitempty"
+			// optional structs are pointers, which also makes recursive types possible
+			if prop.Ref != "" {
+				typ = "*" + typ
+			}
+		}
+		fmt.Fprintf(&sb, "%s %s `json:%q`\n", exportName(p), typ, tag)
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// exportName convert name to an exported go identifier, etc.: getUser --> GetUser, user_id --> UserId
+func exportName(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	s := sb.String()
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "X" + s
+	}
+	return s
+}
+
+// paramName convert name to an unexported go identifier
+func paramName(name string) string {
+	s := exportName(name)
+	s = strings.ToLower(s[:1]) + s[1:]
+	switch s {
+	case "ctx", "params", "body", "path", "query", "header", "out", "type", "func", "range", "map":
+		s += "_"
+	}
+	return s
+}
+
+// clientRuntime is the fixed part of the generated client
+const clientRuntime = `// Client calls the APIs of an EasyGin server
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Header is added to every request
+	Header http.Header
+}
+
+// New create a client of the server at baseURL, etc.: http://127.0.0.1:8080
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		Header:     http.Header{},
+	}
+}
+
+// envelope is the response body of EasyGin
+type envelope struct {
+	Data    json.RawMessage ` + "`json:\"data\"`" + `
+	Code    int             ` + "`json:\"code\"`" + `
+	Message string          ` + "`json:\"message\"`" + `
+}
+
+// do send the request and decode the data of the envelope into out,
+// easygin.RespError is returned if the code of the envelope is not easygin.SuccessCode
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body, out interface{}) error {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for k, vs := range c.Header {
+		req.Header[k] = vs
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", easygin.ContentTypeJson)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var env envelope
+	if err = json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return fmt.Errorf("%s %s: status %d: %w", method, path, resp.StatusCode, err)
+	}
+	if env.Code != easygin.SuccessCode {
+		return easygin.NewError(env.Code, env.Message)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: unexpected status %d", method, path, resp.StatusCode)
+	}
+	if len(env.Data) == 0 || string(env.Data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(env.Data, out)
+}
+
+// addParam add the parameter to values, zero values are skipped unless the parameter is required
+func addParam(values map[string][]string, name string, v interface{}, required bool) {
+	rv := reflect.ValueOf(v)
+	if !required && rv.IsZero() {
+		return
+	}
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		for i := 0; i < rv.Len(); i++ {
+			values[name] = append(values[name], fmt.Sprint(rv.Index(i).Interface()))
+		}
+		return
+	}
+	values[name] = append(values[name], fmt.Sprint(v))
+}
+
+`
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mangohow/easygin"
+)
+
+type User struct {
+	Id       int      `json:"id" uri:"id"`
+	Username string   `json:"username" binding:"required"`
+	Tags     []string `json:"tags"`
+}
+
+type GetUserReq struct {
+	Id int `uri:"id"`
+}
+
+type ListUserReq struct {
+	Page  int    `form:"page"`
+	Token string `header:"X-Token"`
+}
+
+var notFoundErr = easygin.NewError(4, "not found")
+
+func testServer() *easygin.EasyGin {
+	server := easygin.New()
+	server.GET("/user/:id", easygin.Returns[User](), easygin.Errors(notFoundErr), func(req *GetUserReq) *easygin.Response {
+		if req.Id == 0 {
+			return easygin.Fail(notFoundErr)
+		}
+		return easygin.OkData(&User{Id: req.Id, Username: "ape"})
+	})
+	server.GET("/users", easygin.Returns[[]User](), func(ctx *gin.Context, req *ListUserReq) *easygin.Response {
+		return easygin.OkData([]User{{Id: req.Page, Username: req.Token}})
+	})
+	server.POST("/user", func(user *User) *easygin.Response {
+		return easygin.Ok()
+	})
+	return server
+}
+
+func TestGenerateClient(t *testing.T) {
+	data, err := json.Marshal(testServer().OpenAPI(easygin.OpenAPIConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &easygin.OpenAPIDocument{}
+	if err = json.Unmarshal(data, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generateClient(doc, "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"func (c *Client) GetUserId(ctx context.Context, id int64) (*User, error)",
+		"func (c *Client) GetUsers(ctx context.Context, params *GetUsersParams) (*[]User, error)",
+		"func (c *Client) PostUser(ctx context.Context, body *User) (json.RawMessage, error)",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("generated client does not contain %q:\n%s", s, src)
+		}
+	}
+}
+
+func TestGenerateClientNames(t *testing.T) {
+	// the schemas colliding with the identifiers of the client are renamed
+	doc := &easygin.OpenAPIDocument{}
+	if err := json.Unmarshal([]byte(`{
+		"paths": {"/users": {"get": {"operationId": "getUsers",
+			"parameters": [{"name": "page", "in": "query", "schema": {"type": "integer"}}],
+			"responses": {"200": {"content": {"application/json": {"schema": {"type": "object",
+				"properties": {"data": {"$ref": "#/components/schemas/Client"}}}}}}}}}},
+		"components": {"schemas": {
+			"Client": {"type": "object", "properties": {"name": {"type": "string"}}},
+			"client": {"type": "object", "properties": {"id": {"type": "integer"}}},
+			"GetUsersParams": {"type": "object", "properties": {"page": {"type": "integer"}}}
+		}}
+	}`), doc); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generateClient(doc, "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"type Client2 struct",
+		"type Client3 struct",
+		"type GetUsersParams2 struct",
+		"type GetUsersParams struct",
+		"func (c *Client) GetUsers(ctx context.Context, params *GetUsersParams) (*Client2, error)",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("generated client does not contain %q:\n%s", s, src)
+		}
+	}
+	declared := make(map[string]bool)
+	for _, line := range strings.Split(string(src), "\n") {
+		if strings.HasPrefix(line, "type ") {
+			name := strings.Fields(line)[1]
+			if declared[name] {
+				t.Errorf("type %s is declared twice", name)
+			}
+			declared[name] = true
+		}
+	}
+}
+
+// TestGeneratedClient compiles the generated client and calls a test server with it
+func TestGeneratedClient(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated client with the go command")
+	}
+
+	ts := httptest.NewServer(testServer())
+	defer ts.Close()
+
+	doc := testServer().OpenAPI(easygin.OpenAPIConfig{})
+	src, err := generateClient(doc, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the package must be inside the module to import easygin
+	dir, err := os.MkdirTemp(".", "clientgen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = os.WriteFile(filepath.Join(dir, "client.go"), src, 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "main.go"), []byte(`package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mangohow/easygin"
+)
+
+func main() {
+	c := New(os.Args[1])
+	user, err := c.GetUserId(context.Background(), 3)
+	fmt.Println(user.Id, user.Username, err)
+	_, err = c.GetUserId(context.Background(), 0)
+	fmt.Println(err, easygin.IsRespError(err))
+	users, err := c.GetUsers(context.Background(), &GetUsersParams{Page: 2, XToken: "token"})
+	fmt.Println((*users)[0].Id, (*users)[0].Username, err)
+}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("go", "run", "./"+dir, ts.URL).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	expect := "3 ape <nil>\n[4]not found true\n2 token <nil>\n"
+	if string(out) != expect {
+		t.Errorf("expect %q, got %q", expect, out)
+	}
+}
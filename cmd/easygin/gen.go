@@ -0,0 +1,467 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	easyginPath = "github.com/mangohow/easygin"
+	ginPath     = "github.com/gin-gonic/gin"
+)
+
+func runGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	dir := fs.String("dir", ".", "directory of the package which contains the handlers")
+	out := fs.String("o", "easygin_adapters_gen.go", "output file, relative to -dir")
+	tags := fs.String("tags", "", "build constraint of the generated file, etc.: easygin_gen")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	output := filepath.Join(*dir, *out)
+	pkg, handlers, err := scanHandlers(*dir, output)
+	if err != nil {
+		return err
+	}
+	if len(handlers) == 0 {
+		return errors.New("no handler found in " + *dir)
+	}
+
+	src, err := generateAdapters(pkg, handlers, *tags)
+	if err != nil {
+		return err
+	}
+	return writeOutput(output, src)
+}
+
+type paramKind int
+
+const (
+	paramContext paramKind = iota
+	paramStruct
+	paramInt
+	paramUint
+	paramString
+)
+
+type handlerParam struct {
+	kind    paramKind
+	pointer bool
+	// typ is the type expression of the param without the pointer, etc.: User, model.User, int8
+	typ string
+}
+
+type handlerFunc struct {
+	name   string
+	params []handlerParam
+}
+
+// scanHandlers parse the go files in dir and return the package level functions in the form of Handler,
+// imports used by the parameter types are recorded in the returned handlers
+func scanHandlers(dir, output string) (string, []*generatedHandlers, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && filepath.Join(dir, fi.Name()) != output
+	}, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(pkgs) != 1 {
+		return "", nil, fmt.Errorf("expect one package in %s, found %d", dir, len(pkgs))
+	}
+
+	var (
+		pkgName string
+		files   []*generatedHandlers
+	)
+	for name, pkg := range pkgs {
+		pkgName = name
+		fileNames := make([]string, 0, len(pkg.Files))
+		for fileName := range pkg.Files {
+			fileNames = append(fileNames, fileName)
+		}
+		sort.Strings(fileNames)
+		types := &packageTypes{local: localTypes(pkg.Files), foreign: newTypeResolver(dir)}
+		for _, fileName := range fileNames {
+			if gh := scanFile(pkg.Files[fileName], types); len(gh.funcs) > 0 {
+				files = append(files, gh)
+			}
+		}
+	}
+	return pkgName, files, nil
+}
+
+// generatedHandlers is the handlers found in a file and the imports they use
+type generatedHandlers struct {
+	funcs   []handlerFunc
+	imports map[string]string // name --> path
+}
+
+// packageTypes is the types used by the handlers of the package
+type packageTypes struct {
+	local   map[string]localType
+	foreign *typeResolver
+}
+
+// localType is a type declared in the package and the imports of its file
+type localType struct {
+	expr    ast.Expr
+	imports map[string]string
+}
+
+// localTypes return the types declared in the package, generic types are excluded
+func localTypes(files map[string]*ast.File) map[string]localType {
+	types := make(map[string]localType)
+	for _, file := range files {
+		imports := fileImports(file)
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				if ts := spec.(*ast.TypeSpec); ts.TypeParams == nil {
+					types[ts.Name.Name] = localType{expr: ts.Type, imports: imports}
+				}
+			}
+		}
+	}
+	return types
+}
+
+// fileImports return the imports of the file, name --> path
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, imp := range file.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil {
+			imports[imp.Name.Name] = p
+		} else {
+			imports[importName(p)] = p
+		}
+	}
+	return imports
+}
+
+// typeResolver resolve the types of the other packages from their export data, which is built by go list in dir.
+// the types which can not be resolved are not supported, their handlers are bound by reflection
+type typeResolver struct {
+	dir      string
+	importer types.Importer
+}
+
+func newTypeResolver(dir string) *typeResolver {
+	r := &typeResolver{dir: dir}
+	r.importer = importer.ForCompiler(token.NewFileSet(), "gc", r.lookup)
+	return r
+}
+
+func (r *typeResolver) lookup(path string) (io.ReadCloser, error) {
+	cmd := exec.Command("go", "list", "-export", "-f", "{{.Export}}", path)
+	cmd.Dir = r.dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(strings.TrimSpace(string(out)))
+}
+
+// kind classify the type name of the package path by its underlying type, the same as identKind
+func (r *typeResolver) kind(path, name string) (paramKind, bool) {
+	pkg, err := r.importer.Import(path)
+	if err != nil {
+		return 0, false
+	}
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok || !obj.Exported() {
+		return 0, false
+	}
+	if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+		return 0, false
+	}
+	switch t := obj.Type().Underlying().(type) {
+	case *types.Struct:
+		return paramStruct, true
+	case *types.Basic:
+		switch t.Kind() {
+		case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+			return paramInt, true
+		case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+			return paramUint, true
+		case types.String:
+			return paramString, true
+		}
+	}
+	return 0, false
+}
+
+func scanFile(file *ast.File, types *packageTypes) *generatedHandlers {
+	imports := fileImports(file)
+
+	gh := &generatedHandlers{imports: make(map[string]string)}
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Recv != nil || fd.Type.TypeParams != nil {
+			continue
+		}
+		if !returnsResponse(fd.Type.Results, imports) {
+			continue
+		}
+
+		hf := handlerFunc{name: fd.Name.Name}
+		supported := true
+		for _, field := range fd.Type.Params.List {
+			p, pkg, ok := classifyParam(field.Type, imports, types)
+			if !ok {
+				supported = false
+				break
+			}
+			if pkg != "" {
+				gh.imports[pkg] = imports[pkg]
+			}
+			n := len(field.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				hf.params = append(hf.params, p)
+			}
+		}
+		if supported {
+			gh.funcs = append(gh.funcs, hf)
+		}
+	}
+	return gh
+}
+
+// importName guess the package name of the import path, etc.:
+// github.com/elliotchance/pie/v2 --> pie, gopkg.in/yaml.v3 --> yaml
+func importName(p string) string {
+	elems := strings.Split(p, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' {
+		if _, err := strconv.Atoi(name[1:]); err == nil {
+			name = elems[len(elems)-2]
+		}
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// returnsResponse report whether the results is *easygin.Response
+func returnsResponse(results *ast.FieldList, imports map[string]string) bool {
+	if results == nil || len(results.List) != 1 || len(results.List[0].Names) > 1 {
+		return false
+	}
+	star, ok := results.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Response" {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && imports[x.Name] == easyginPath
+}
+
+// classifyParam return the param and the import name it uses, the types are classified by their underlying types,
+// the types of the other packages are resolved by the typeResolver
+func classifyParam(expr ast.Expr, imports map[string]string, types *packageTypes) (handlerParam, string, bool) {
+	var p handlerParam
+	if star, ok := expr.(*ast.StarExpr); ok {
+		p.pointer = true
+		expr = star.X
+	}
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		p.typ = t.Name
+		kind, ok := identKind(t.Name, types, 0)
+		p.kind = kind
+		return p, "", ok
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		if !ok {
+			return p, "", false
+		}
+		if imports[x.Name] == ginPath && t.Sel.Name == "Context" {
+			if !p.pointer {
+				return p, "", false
+			}
+			p.kind = paramContext
+			return p, "", true
+		}
+		kind, ok := selectorKind(t, imports, types)
+		p.kind = kind
+		p.typ = x.Name + "." + t.Sel.Name
+		return p, x.Name, ok
+	}
+	return p, "", false
+}
+
+// identKind classify the type by its underlying type, the same as the kind used by easygin.bindParam
+func identKind(name string, types *packageTypes, depth int) (paramKind, bool) {
+	switch name {
+	case "int", "int8", "int16", "int32", "int64":
+		return paramInt, true
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		return paramUint, true
+	case "string":
+		return paramString, true
+	}
+
+	// depth stops the recursive declarations which are invalid
+	lt, ok := types.local[name]
+	if !ok || depth > len(types.local) {
+		return 0, false
+	}
+	switch t := lt.expr.(type) {
+	case *ast.StructType:
+		return paramStruct, true
+	case *ast.SelectorExpr:
+		// alias or definition of a type of another package
+		return selectorKind(t, lt.imports, types)
+	case *ast.Ident:
+		return identKind(t.Name, types, depth+1)
+	}
+	return 0, false
+}
+
+// selectorKind classify the type of another package, etc.: time.Duration
+func selectorKind(sel *ast.SelectorExpr, imports map[string]string, types *packageTypes) (paramKind, bool) {
+	x, ok := sel.X.(*ast.Ident)
+	if !ok || imports[x.Name] == "" {
+		return 0, false
+	}
+	return types.foreign.kind(imports[x.Name], sel.Sel.Name)
+}
+
+// generateAdapters generate the adapters of the handlers, they are registered in the init function
+func generateAdapters(pkg string, files []*generatedHandlers, tags string) ([]byte, error) {
+	imports := map[string]string{"easygin": easyginPath, "gin": ginPath}
+	var body bytes.Buffer
+	for _, gh := range files {
+		for name, p := range gh.imports {
+			if old, ok := imports[name]; ok && old != p {
+				return nil, fmt.Errorf("import name %s is used by both %s and %s", name, old, p)
+			}
+			imports[name] = p
+		}
+		for _, hf := range gh.funcs {
+			if adapterUsesStrconv(hf) {
+				imports["strconv"] = "strconv"
+			}
+			writeAdapter(&body, hf)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by easygin gen. DO NOT EDIT.\n\n")
+	if tags != "" {
+		fmt.Fprintf(&buf, "//go:build %s\n\n", tags)
+	}
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n", pkg)
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := imports[name]
+		if p == name || strings.HasSuffix(p, "/"+name) {
+			fmt.Fprintf(&buf, "%q\n", p)
+		} else {
+			fmt.Fprintf(&buf, "%s %q\n", name, p)
+		}
+	}
+	buf.WriteString(")\n\nfunc init() {\n")
+	buf.Write(body.Bytes())
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated adapters: %w\n%s", err, buf.String())
+	}
+	return src, nil
+}
+
+func adapterUsesStrconv(hf handlerFunc) bool {
+	for _, p := range hf.params {
+		if p.kind == paramInt || p.kind == paramUint {
+			return true
+		}
+	}
+	return false
+}
+
+// writeAdapter write the registration of the adapter of hf, the binding is the same as easygin.bindParam
+func writeAdapter(w *bytes.Buffer, hf handlerFunc) {
+	fmt.Fprintf(w, "easygin.RegisterAdapter(%s, func(ctx *gin.Context) (func() *easygin.Response, error) {\n", hf.name)
+
+	args := make([]string, 0, len(hf.params))
+	queryCreated := false
+	for i, p := range hf.params {
+		v := "p" + strconv.Itoa(i)
+		switch p.kind {
+		case paramContext:
+			args = append(args, "ctx")
+			continue
+		case paramStruct:
+			if p.pointer {
+				fmt.Fprintf(w, "%s := new(%s)\n", v, p.typ)
+				fmt.Fprintf(w, "if err := easygin.BindStruct(ctx, %s); err != nil {\nreturn nil, err\n}\n", v)
+			} else {
+				fmt.Fprintf(w, "var %s %s\n", v, p.typ)
+				fmt.Fprintf(w, "if err := easygin.BindStruct(ctx, &%s); err != nil {\nreturn nil, err\n}\n", v)
+			}
+			args = append(args, v)
+			continue
+		}
+
+		if !queryCreated {
+			fmt.Fprintf(w, "query := easygin.NewQuery(ctx)\n")
+			queryCreated = true
+		}
+		fmt.Fprintf(w, "s%d, err := query.Next()\nif err != nil {\nreturn nil, err\n}\n", i)
+		value := "s" + strconv.Itoa(i)
+		switch p.kind {
+		case paramInt:
+			fmt.Fprintf(w, "n%d, err := strconv.ParseInt(s%d, 10, 64)\nif err != nil {\nreturn nil, err\n}\n", i, i)
+			value = fmt.Sprintf("%s(n%d)", p.typ, i)
+		case paramUint:
+			fmt.Fprintf(w, "n%d, err := strconv.ParseUint(s%d, 10, 64)\nif err != nil {\nreturn nil, err\n}\n", i, i)
+			value = fmt.Sprintf("%s(n%d)", p.typ, i)
+		case paramString:
+			if p.typ != "string" {
+				value = fmt.Sprintf("%s(s%d)", p.typ, i)
+			}
+		}
+		if p.pointer {
+			fmt.Fprintf(w, "%s := new(%s)\n*%s = %s\n", v, p.typ, v, value)
+		} else {
+			fmt.Fprintf(w, "%s := %s\n", v, value)
+		}
+		args = append(args, v)
+	}
+
+	fmt.Fprintf(w, "return func() *easygin.Response {\nreturn %s(%s)\n}, nil\n})\n\n", hf.name, strings.Join(args, ", "))
+}
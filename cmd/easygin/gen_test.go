@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const handlersSrc = `package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	eg "github.com/mangohow/easygin"
+)
+
+type User struct {
+	Id       int    ` + "`json:\"id\" uri:\"id\"`" + `
+	Username string ` + "`json:\"username\" form:\"username\" binding:\"required\"`" + `
+}
+
+func GetUser(ctx *gin.Context, user *User) *eg.Response {
+	return eg.OkData(user)
+}
+
+func ListUsers(page uint8, name *string) *eg.Response {
+	return eg.OkData([]User{{Id: int(page), Username: *name}})
+}
+
+type (
+	UserID int64
+	Name   string
+	Nick   = Name
+	Flag   bool
+)
+
+func GetByID(id UserID, name *Nick) *eg.Response {
+	return eg.OkData(fmt.Sprint(id, *name))
+}
+
+// not supported, it is bound by reflection
+func SetFlag(flag Flag) *eg.Response {
+	return eg.OkData(flag)
+}
+
+type Timeout time.Duration
+
+func Wait(d time.Duration, timeout *Timeout) *eg.Response {
+	return eg.OkData(fmt.Sprint(d, time.Duration(*timeout)))
+}
+
+// not supported, it is bound by reflection
+func GetHeader(header http.Header) *eg.Response {
+	return eg.OkData(len(header))
+}
+
+func Ping() *eg.Response {
+	return eg.Ok()
+}
+
+// not a handler
+func helper(user User) string {
+	return user.Username
+}
+
+func main() {
+	server := eg.New()
+	server.GET("/user/:id", GetUser)
+	server.GET("/users", ListUsers)
+	server.GET("/ping", Ping)
+	server.GET("/id", GetByID)
+	server.GET("/flag", SetFlag)
+	server.GET("/wait", Wait)
+	server.GET("/header", GetHeader)
+	for _, path := range []string{"/wait?d=5&timeout=1000", "/wait?d=a", "/header", "/user/3?username=ape", "/user/3", "/users?page=2&name=ape", "/users?page=a", "/ping", "/id?id=7&name=ape", "/flag?flag=true"} {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		fmt.Println(w.Code, w.Body.String())
+	}
+}
+`
+
+func writeHandlers(t *testing.T) string {
+	// the package must be inside the module to import easygin
+	dir, err := os.MkdirTemp(".", "adaptergen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	if err = os.WriteFile(filepath.Join(dir, "main.go"), []byte(handlersSrc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestGenerateAdapters(t *testing.T) {
+	dir := writeHandlers(t)
+	pkg, handlers, err := scanHandlers(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generateAdapters(pkg, handlers, "easygin_gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"//go:build easygin_gen",
+		"easygin.RegisterAdapter(GetUser, func(ctx *gin.Context) (func() *easygin.Response, error) {",
+		"if err := easygin.BindStruct(ctx, p1); err != nil {",
+		"n0, err := strconv.ParseUint(s0, 10, 64)",
+		"p0 := uint8(n0)",
+		"return ListUsers(p0, p1)",
+		"return Ping()",
+		"p0 := UserID(n0)",
+		"*p1 = Nick(s1)",
+		"p0 := time.Duration(n0)",
+		"*p1 = Timeout(n1)",
+		`"time"`,
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("generated adapters do not contain %q:\n%s", s, src)
+		}
+	}
+	if strings.Contains(string(src), "helper") || strings.Contains(string(src), "SetFlag") || strings.Contains(string(src), "GetHeader") {
+		t.Errorf("generated adapters contain a function which is not a handler:\n%s", src)
+	}
+}
+
+// TestGeneratedAdapters compares the responses of the routes with and without the generated adapters
+func TestGeneratedAdapters(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated adapters with the go command")
+	}
+
+	dir := writeHandlers(t)
+	if err := runGen([]string{"-dir", dir, "-tags", "easygin_gen"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reflective, err := exec.Command("go", "run", "./"+dir).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, reflective)
+	}
+	generated, err := exec.Command("go", "run", "-tags", "easygin_gen", "./"+dir).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, generated)
+	}
+	if string(generated) != string(reflective) {
+		t.Errorf("generated adapters respond:\n%s\nreflection responds:\n%s", generated, reflective)
+	}
+	if !strings.Contains(string(generated), `200 {"data":{"id":3,"username":"ape"},"code":0,"message":"success"}`) {
+		t.Errorf("unexpected responses:\n%s", generated)
+	}
+}
@@ -0,0 +1,69 @@
+// Command easygin contains the code generators of easygin.
+//
+//	easygin gen -dir ./controller -tags easygin_gen
+//
+// scans the package level functions in the form of easygin.Handler and generates adapters binding
+// their parameters without reflection, they are registered by easygin.RegisterAdapter in the init function,
+// so the routes are still registered through EasyGin.GET etc. with -tags, the generated adapters are only
+// used by the builds with the tag, and the other builds keep the reflective path.
+//
+//	easygin client -spec openapi.json -pkg client -o client_gen.go
+//
+// generates a typed Go client from the OpenAPI document served by EasyGin.ServeOpenAPI,
+// -spec can be a file or an http url, it can be used with go:generate:
+//
+//	//go:generate go run github.com/mangohow/easygin/cmd/easygin client -spec ../api/openapi.json -pkg client -o client_gen.go
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "client", usage: "generate a typed Go client from an OpenAPI document", run: runClient},
+	{name: "gen", usage: "generate reflection-free adapters of the handlers in a package", run: runGen},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "easygin %s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: easygin <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.usage)
+	}
+}
+
+// writeOutput write the generated source to file, or stdout if file is empty
+func writeOutput(file string, src []byte) error {
+	if file == "" {
+		_, err := os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(file, src, 0644)
+}
@@ -4,18 +4,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
+	"path"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/elliotchance/pie/v2"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 /*
@@ -45,39 +50,99 @@ import (
 		3.1 func mycontroller(ctx *gin.Context, id int, username string) *Response
 	NOTE: using this method can significantly reduce data acquisition performance, 50ns/op --> 1800ns/op
 		  but for the business, this loss can be negligible
+
+	4. fields of struct with uri or header tags are bound from path params or headers before the above binding:
+		type GetUser struct {
+			ID    int    `uri:"id"`
+			Token string `header:"X-Token"`
+		}
+		4.1 e.GET("/user/:id", func(ctx *gin.Context, u *GetUser) *Response)
 */
 
 type EasyGin struct {
 	*gin.Engine
-	Server             *http.Server
-	signalHandler      func() context.Context
-	afterCloseHandlers []func()
-	maxGraceDuration   time.Duration
+	Server           *http.Server
+	signalHandler    func() context.Context
+	signals          *SignalManager
+	lifecycle        Lifecycle
+	maxGraceDuration time.Duration
+	// afterDrainReserve is the part of maxGraceDuration reserved for the AfterDrain hooks
+	afterDrainReserve time.Duration
+	logger            Logger
+	tracer            trace.Tracer
+	propagator        propagation.TextMapPropagator
+	metrics           *metrics
+	registry          registry
+	versionings       []*Versions
+	prepareOnce       sync.Once
+	// listeners are served together by Serve, they are opened by Listen and guarded by listenersMu
+	listenersMu sync.Mutex
+	listeners   []*serverListener
+	// gracefulRestart is true if SIGHUP restarts the process gracefully
+	gracefulRestart bool
+	health          health
+	// shuttingDown is true after the shutdown starts, the readiness fails
+	shuttingDown atomic.Bool
+	// stop is closed to start shutting down, drained is closed after the shutdown finishes with shutdownErr
+	stop         chan struct{}
+	drained      chan struct{}
+	stopOnce     sync.Once
+	shutdownOnce sync.Once
+	shutdownErr  error
+
+	// root is the RouterGroup of the Engine, routes registered through EasyGin are delegated to it
+	root *RouterGroup
 }
 
 type RouterGroup struct {
 	*gin.RouterGroup
+	engine *EasyGin
+	// middlewares wrap the typed handlers of the routes registered after Use
+	middlewares []Middleware
+	// interceptors are called with the Response of the routes registered after Intercept
+	interceptors []Interceptor
+	// version is the version the group belongs to
+	version *VersionGroup
+	// timeout is the timeout of the routes registered after SetTimeout
+	timeout *TimeoutConfig
+	// guards check the requests of the routes registered after them, etc.: RateLimit
+	guards []guard
 }
 
 func New() *EasyGin {
-	return &EasyGin{
-		Engine:           gin.New(),
-		maxGraceDuration: time.Second * 10,
+	e := &EasyGin{
+		Engine:            gin.New(),
+		maxGraceDuration:  time.Second * 10,
+		afterDrainReserve: time.Second * 2,
 	}
+	e.root = &RouterGroup{RouterGroup: &e.Engine.RouterGroup, engine: e}
+	e.lifecycle.logger = e.Logger
+	e.stop, e.drained = make(chan struct{}), make(chan struct{})
+	return e
 }
 
 func NewWithEngine(r *gin.Engine) *EasyGin {
-	return &EasyGin{Engine: r}
+	e := &EasyGin{
+		Engine:            r,
+		maxGraceDuration:  time.Second * 10,
+		afterDrainReserve: time.Second * 2,
+	}
+	e.root = &RouterGroup{RouterGroup: &r.RouterGroup, engine: e}
+	e.lifecycle.logger = e.Logger
+	e.stop, e.drained = make(chan struct{}), make(chan struct{})
+	return e
 }
 
+// SetMaxGraceDuration set the time the shutdown waits for the draining and the hooks, default is 10s.
+// the AfterDrain hooks have their own max, the shutdown has no deadline if max is 0
 func (e *EasyGin) SetMaxGraceDuration(max time.Duration) {
 	e.maxGraceDuration = max
 }
 
-var elog = log.New(os.Stderr, "EasyGin", log.LstdFlags)
-
-func SetLogOutput(out io.Writer) {
-	elog.SetOutput(out)
+// SetAfterDrainReserve set the part of the max grace duration reserved for the AfterDrain hooks, the BeforeDrain hooks
+// and the draining end before it. it is at most half of the max grace duration, default is 2s
+func (e *EasyGin) SetAfterDrainReserve(reserve time.Duration) {
+	e.afterDrainReserve = reserve
 }
 
 // Handler must be in one of the following forms
@@ -90,103 +155,279 @@ func SetLogOutput(out io.Writer) {
 type Handler interface{}
 
 func (e *EasyGin) GET(relativePath string, handlers ...Handler) {
-	e.Engine.GET(relativePath, ginHandlers(handlers...)...)
+	e.root.GET(relativePath, handlers...)
 }
 
 func (e *EasyGin) POST(relativePath string, handlers ...Handler) {
-	e.Engine.POST(relativePath, ginHandlers(handlers...)...)
+	e.root.POST(relativePath, handlers...)
 }
 
 func (e *EasyGin) DELETE(relativePath string, handlers ...Handler) {
-	e.Engine.DELETE(relativePath, ginHandlers(handlers...)...)
+	e.root.DELETE(relativePath, handlers...)
 }
 
 func (e *EasyGin) HEAD(relativePath string, handlers ...Handler) {
-	e.Engine.HEAD(relativePath, ginHandlers(handlers...)...)
+	e.root.HEAD(relativePath, handlers...)
 }
 
 func (e *EasyGin) PATCH(relativePath string, handlers ...Handler) {
-	e.Engine.PATCH(relativePath, ginHandlers(handlers...)...)
+	e.root.PATCH(relativePath, handlers...)
 }
 
 func (e *EasyGin) PUT(relativePath string, handlers ...Handler) {
-	e.Engine.PUT(relativePath, ginHandlers(handlers...)...)
+	e.root.PUT(relativePath, handlers...)
+}
+
+func (e *EasyGin) OPTIONS(relativePath string, handlers ...Handler) {
+	e.root.OPTIONS(relativePath, handlers...)
+}
+
+func (e *EasyGin) Any(relativePath string, handlers ...Handler) {
+	e.root.Any(relativePath, handlers...)
+}
+
+func (e *EasyGin) Handle(httpMethod, relativePath string, handlers ...Handler) {
+	e.root.Handle(httpMethod, relativePath, handlers...)
+}
+
+func (e *EasyGin) Match(methods []string, relativePath string, handlers ...Handler) {
+	e.root.Match(methods, relativePath, handlers...)
+}
+
+func (e *EasyGin) Static(relativePath, root string) {
+	e.root.Static(relativePath, root)
+}
+
+func (e *EasyGin) StaticFS(relativePath string, fs http.FileSystem) {
+	e.root.StaticFS(relativePath, fs)
+}
+
+// NoRoute set the handlers for requests matching no route, etc.:
+//
+//	e.NoRoute(func() *Response { return FailStatus(http.StatusNotFound, RespNotFound) })
+func (e *EasyGin) NoRoute(handlers ...Handler) {
+	e.Engine.NoRoute(ginHandlers(handlers...)...)
+}
+
+// NoMethod set the handlers for requests matching a route with another method,
+// HandleMethodNotAllowed of the Engine is enabled, otherwise gin handles them as NoRoute
+func (e *EasyGin) NoMethod(handlers ...Handler) {
+	e.Engine.HandleMethodNotAllowed = true
+	e.Engine.NoMethod(ginHandlers(handlers...)...)
 }
 
 func (e *EasyGin) Group(relativePath string, handlers ...Handler) *RouterGroup {
-	group := e.Engine.Group(relativePath, ginHandlers(handlers...)...)
-	return &RouterGroup{group}
+	return e.root.Group(relativePath, handlers...)
 }
 
-// SetSignalHandler set signal processing functions
-// if the signal processing function is set when calling this method,
-// the registered afterCloseHandlers will not work
+// Use attach middlewares to the Engine, see RouterGroup.Use
+func (e *EasyGin) Use(middlewares ...Handler) {
+	ginMiddlewares, typed := splitMiddlewares(middlewares)
+	// Engine.Use rebuilds the handlers of NoRoute and NoMethod
+	e.Engine.Use(ginMiddlewares...)
+	e.root.middlewares = append(e.root.middlewares, typed...)
+}
+
+// SetSignalHandler set signal processing functions, the server shuts down when the returned context is done
 func (e *EasyGin) SetSignalHandler(f func() context.Context) {
 	e.signalHandler = f
 }
 
-// SetAfterCloseHandlers register handlers which will be called after server closed
+// SetAfterCloseHandlers register handlers which will be called after server closed,
+// they are AfterDrain shutdown hooks, see Lifecycle
 func (e *EasyGin) SetAfterCloseHandlers(handlers ...func()) {
-	e.afterCloseHandlers = append(e.afterCloseHandlers, handlers...)
+	for _, handler := range handlers {
+		handler := handler
+		e.lifecycle.Add(ShutdownHook{Name: "afterClose", Phase: AfterDrain, Hook: func(context.Context) error {
+			handler()
+			return nil
+		}})
+	}
 }
 
-func (e *EasyGin) setupSignal() {
+// setupSignal shut down the servers when the signal handler is done, the returned channel is closed after they are drained
+func (e *EasyGin) setupSignal() <-chan struct{} {
 	if e.signalHandler == nil {
-		e.signalHandler = SetupSignalHandler
+		e.signalHandler = func() context.Context {
+			return e.SignalManager().Default(e.Logger())
+		}
 	}
 
 	ctx := e.signalHandler()
 
 	go func() {
-		<-ctx.Done()
+		stop := e.waitShutdown(ctx)
+		e.runShutdown()
+		// the signal of the graceful restart is handled until the servers are drained, it terminates the process otherwise
+		stop()
+	}()
+	return e.drained
+}
 
-		for _, handler := range e.afterCloseHandlers {
-			handler()
-		}
+// EnableGracefulRestart restart the process without dropping connections on SIGHUP: a new process of the same
+// command is started with the listening sockets, which are used by its Listen of the same addresses,
+// then the old process shuts down gracefully within maxGraceDuration. the instances sharing a SignalManager
+// are restarted together by one new process with the sockets of all of them
+func (e *EasyGin) EnableGracefulRestart() {
+	e.gracefulRestart = true
+}
 
-		ctx, cancelFunc := context.WithTimeout(context.Background(), e.maxGraceDuration)
-		defer cancelFunc()
-		if err := e.Server.Shutdown(ctx); err != nil {
-			elog.Printf("An error occurs when Server shut:%v", err)
-		}
-	}()
+// restartCommand create the command of the new process, it is replaced in tests
+var restartCommand = func() *exec.Cmd {
+	cmd := exec.Command(os.Args[0], os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd
+}
 
+// waitShutdown wait until ctx is done, the shutdown is started or the new process is started by the graceful restart,
+// stop stops handling the signal of the graceful restart
+func (e *EasyGin) waitShutdown(ctx context.Context) (stop func()) {
+	var restart <-chan int
+	stop = func() {}
+	if e.gracefulRestart {
+		restart, stop = e.notifyRestart()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stop:
+			return
+		case pid := <-restart:
+			e.Logger().Log(context.Background(), slog.LevelInfo, "new process started by graceful restart", "pid", pid)
+			return
+		}
+	}
 }
 
+// ListenAndServe listen on addr and serve with the listeners opened by Listen, see Serve
 func (e *EasyGin) ListenAndServe(addr string) error {
 	if e.Server == nil {
 		e.Server = &http.Server{
 			Addr:    addr,
-			Handler: e.Engine,
+			Handler: e,
 		}
 	}
+	if e.Server.Addr == "" {
+		e.Server.Addr = ":http"
+	}
 
-	e.setupSignal()
-	return e.Server.ListenAndServe()
+	if err := e.Listen(ListenConfig{Addr: e.Server.Addr}); err != nil {
+		return err
+	}
+	return e.serve()
+}
+
+// Prepare register the fallthrough routes of the versions, the conflicting routes panic here.
+// it is called before the connections are accepted by the Serve methods, and by RegisteredRoutes and OpenAPI,
+// call it after all the routes are registered if e.Engine serves the requests directly
+func (e *EasyGin) Prepare() {
+	e.prepareOnce.Do(func() {
+		for _, vs := range e.versionings {
+			vs.registerFallthrough()
+		}
+	})
+}
+
+// ServeHTTP select the version of the request before routing if Versioning is used
+func (e *EasyGin) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	e.Prepare()
+	for _, vs := range e.versionings {
+		if !vs.route(w, req) {
+			return
+		}
+	}
+	e.Engine.ServeHTTP(w, req)
 }
 
 func (r *RouterGroup) GET(relativePath string, handlers ...Handler) {
-	r.RouterGroup.GET(relativePath, ginHandlers(handlers...)...)
+	r.handle(http.MethodGet, relativePath, handlers)
 }
 
 func (r *RouterGroup) POST(relativePath string, handlers ...Handler) {
-	r.RouterGroup.POST(relativePath, ginHandlers(handlers...)...)
+	r.handle(http.MethodPost, relativePath, handlers)
 }
 
 func (r *RouterGroup) DELETE(relativePath string, handlers ...Handler) {
-	r.RouterGroup.DELETE(relativePath, ginHandlers(handlers...)...)
+	r.handle(http.MethodDelete, relativePath, handlers)
 }
 
 func (r *RouterGroup) HEAD(relativePath string, handlers ...Handler) {
-	r.RouterGroup.HEAD(relativePath, ginHandlers(handlers...)...)
+	r.handle(http.MethodHead, relativePath, handlers)
 }
 
 func (r *RouterGroup) PATCH(relativePath string, handlers ...Handler) {
-	r.RouterGroup.PATCH(relativePath, ginHandlers(handlers...)...)
+	r.handle(http.MethodPatch, relativePath, handlers)
 }
 
 func (r *RouterGroup) PUT(relativePath string, handlers ...Handler) {
-	r.RouterGroup.PUT(relativePath, ginHandlers(handlers...)...)
+	r.handle(http.MethodPut, relativePath, handlers)
+}
+
+func (r *RouterGroup) OPTIONS(relativePath string, handlers ...Handler) {
+	r.handle(http.MethodOptions, relativePath, handlers)
+}
+
+// anyMethods is the same as the methods registered by gin.RouterGroup.Any
+var anyMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodHead, http.MethodOptions, http.MethodDelete, http.MethodConnect,
+	http.MethodTrace,
+}
+
+// Any register a route that matches all the HTTP methods,
+// every method is registered as a separate route in RegisteredRoutes
+func (r *RouterGroup) Any(relativePath string, handlers ...Handler) {
+	r.Match(anyMethods, relativePath, handlers...)
+}
+
+func (r *RouterGroup) Handle(httpMethod, relativePath string, handlers ...Handler) {
+	r.handle(httpMethod, relativePath, handlers)
+}
+
+// Match register a route that matches the specified methods
+func (r *RouterGroup) Match(methods []string, relativePath string, handlers ...Handler) {
+	for _, method := range methods {
+		r.handle(method, relativePath, handlers)
+	}
+}
+
+// Static serve files from the given file system root, directories are not listed
+func (r *RouterGroup) Static(relativePath, root string) {
+	r.StaticFS(relativePath, gin.Dir(root, false))
+}
+
+// StaticFS serve files from fs in the same way as gin.RouterGroup.StaticFS, the GET and HEAD routes are
+// traced, measured and registered like the other routes, missing files are handled by EasyGin.NoRoute
+func (r *RouterGroup) StaticFS(relativePath string, fs http.FileSystem) {
+	// the method of the route is taken from the request because gin registers GET and HEAD with the same group
+	rt := newRoute(r.engine, "", joinPaths(r.BasePath(), path.Join(relativePath, "/*filepath")))
+	r.RouterGroup.Group("", rt.traceRoute, rt.measureRoute).StaticFS(relativePath, fs)
+	if r.engine != nil {
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			r.engine.registry.add(&RouteInfo{Method: method, Path: rt.path, Handler: fmt.Sprintf("StaticFS(%T)", fs)})
+		}
+	}
+}
+
+func (r *RouterGroup) handle(httpMethod, relativePath string, handlers []Handler) {
+	rt := newRoute(r.engine, httpMethod, joinPaths(r.BasePath(), relativePath))
+	rt.middlewares = r.middlewares[:len(r.middlewares):len(r.middlewares)]
+	rt.interceptors = r.interceptors[:len(r.interceptors):len(r.interceptors)]
+	rt.timeout = r.timeout
+	rt.guards = r.guards[:len(r.guards):len(r.guards)]
+	rest := rt.applyOptions(handlers)
+	rt.guards = orderGuards(rt.guards)
+	ginHandlers := rt.ginHandlers(rest)
+	rt.info.Authorization = authorizations(rt.guards)
+	r.RouterGroup.Handle(httpMethod, relativePath, ginHandlers...)
+	// registered after gin which panics on invalid methods and conflicting paths
+	if r.engine != nil {
+		r.engine.registry.add(rt.info)
+	}
+	if r.version != nil {
+		r.version.record(r, httpMethod, rt.path, handlers)
+	}
 }
 
 var (
@@ -198,57 +439,156 @@ const (
 	ContentTypeJson = "application/json"
 )
 
+// ginHandlers convert handlers which are not bound to a route, such as the handlers of groups
 func ginHandlers(handlers ...Handler) []gin.HandlerFunc {
+	return newHandlers(nil, handlers)
+}
+
+func newHandlers(rt *route, handlers []Handler) []gin.HandlerFunc {
 	if len(handlers) == 0 {
 		return nil
 	}
-	return pie.Map(handlers, func(handler Handler) gin.HandlerFunc {
-		fv := reflect.ValueOf(handler)
-		ft := fv.Type()
-		if ft.Kind() != reflect.Func {
-			panic("handler must be func type")
-		}
-
-		// 检查返回值类型，返回值必须只有一个，并且是*Result类型
-		if ft.NumOut() == 0 || ft.NumOut() > 1 {
-			panic("return value must have one and be *Response type")
-		}
-		outt := ft.Out(0)
-		if outt != outType {
-			panic("return value must be *Response type")
-		}
-
-		return func(ctx *gin.Context) {
-			// 入参可以有0个或多个
-			inValues := make([]reflect.Value, 0, ft.NumIn())
-			if ft.NumIn() > 0 {
-				queryVals := &queryValues{}
-				for i := 0; i < ft.NumIn(); i++ {
-					in := ft.In(i)
-					// 如果当前类型为*gin.Context，则将ctx注入
-					if in == ginCtxType {
-						inValues = append(inValues, reflect.ValueOf(ctx))
-						continue
-					}
-					// 否则，从gin中取出注入
-					val, err := bindParam(in, ctx, queryVals)
-					if err != nil {
-						return
-					}
-					inValues = append(inValues, val)
-				}
-			}
+	// the typed middlewares, interceptors and timeout only apply to the last handler,
+	// the others act as middlewares of gin
+	n := len(handlers)
+	hs := pie.Map(handlers[:n-1], func(handler Handler) gin.HandlerFunc {
+		return newHandler(rt, handler, false)
+	})
+	return append(hs, newHandler(rt, handlers[n-1], true))
+}
+
+func newHandler(rt *route, handler Handler, last bool) gin.HandlerFunc {
+	fv := reflect.ValueOf(handler)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		panic("handler must be func type")
+	}
+
+	// 检查返回值类型，返回值必须只有一个，并且是*Result类型
+	if ft.NumOut() == 0 || ft.NumOut() > 1 {
+		panic("return value must have one and be *Response type")
+	}
+	outt := ft.Out(0)
+	if outt != outType {
+		panic("return value must be *Response type")
+	}
+	var (
+		middlewares  []Middleware
+		interceptors []Interceptor
+		timeout      *TimeoutConfig
+		guards       []guard
+	)
+	if last && rt != nil {
+		middlewares, interceptors, timeout, guards = rt.middlewares, rt.interceptors, rt.timeout, rt.guards
+	}
+	// the guards run before the last handler only, the others can not have principals
+	if !authenticated(guards) {
+		mustNotUsePrincipal(ft)
+	}
+	name := handlerName(fv)
+	bind := lookupAdapter(fv)
+	if bind == nil {
+		bind = reflectAdapter(fv, ft)
+	}
+
+	// invoke bind the parameters and call the handler, it returns nil if the binding fails
+	invoke := func(ctx *gin.Context) *Response {
+		if resp := runGuards(ctx, guards, false); resp != nil {
+			return resp
+		}
+		tracer := rt.tracer()
+
+		var span trace.Span
+		if tracer != nil {
+			_, span = tracer.Start(ctx.Request.Context(), spanBind)
+		}
+		call, err := bind(ctx)
+		if err != nil {
+			endSpan(span, err)
+			rt.bindFailed()
+			return nil
+		}
+		endSpan(span, nil)
+		if resp := runGuards(ctx, guards, true); resp != nil {
+			return resp
+		}
 
-			outVals := fv.Call(inValues)
-			result := outVals[0].Interface().(*Response)
-			if result == nil {
+		if tracer != nil {
+			return callTraced(tracer, ctx, name, call)
+		}
+		return call()
+	}
+
+	handle := func(ctx *gin.Context) *Response {
+		if len(middlewares) == 0 {
+			return invoke(ctx)
+		}
+		return runMiddlewares(ctx, middlewares, func() *Response {
+			return invoke(ctx)
+		})
+	}
+
+	return func(ctx *gin.Context) {
+		var result *Response
+		if timeout == nil {
+			result = handle(ctx)
+		} else {
+			var ok bool
+			if result, ok = timeout.run(ctx, func() *Response { return handle(ctx) }); !ok {
 				return
 			}
-			ctx.JSON(result.Status, &result.R)
+		}
+		if result == nil {
+			return
+		}
+		id := GetRequestID(ctx)
+		if id != "" {
+			result.R.RespError = attachRequestID(result.R.RespError, id)
+		}
+		if len(interceptors) > 0 {
+			if result = runInterceptors(ctx, interceptors, result); result == nil {
+				return
+			}
+		}
+		if id != "" && ctx.GetBool(requestIDInRespKey) {
+			result.R.RequestID = id
+		}
+		if result.R.RespError != nil {
+			ctx.Set(respCodeKey, result.R.Code())
+		}
+		ctx.JSON(result.Status, &result.R)
 
-			pool.Put(result)
+		pool.Put(result)
+	}
+}
+
+// reflectAdapter bind the parameters and call the handler by reflection
+func reflectAdapter(fv reflect.Value, ft reflect.Type) Adapter {
+	return func(ctx *gin.Context) (func() *Response, error) {
+		// 入参可以有0个或多个
+		inValues := make([]reflect.Value, 0, ft.NumIn())
+		if ft.NumIn() > 0 {
+			queryVals := &queryValues{}
+			for i := 0; i < ft.NumIn(); i++ {
+				in := ft.In(i)
+				// 如果当前类型为*gin.Context，则将ctx注入
+				if in == ginCtxType {
+					inValues = append(inValues, reflect.ValueOf(ctx))
+					continue
+				}
+				// 否则，从gin中取出注入
+				val, err := bindParam(in, ctx, queryVals)
+				if err != nil {
+					return nil, err
+				}
+				inValues = append(inValues, val)
+			}
 		}
-	})
+
+		return func() *Response {
+			return fv.Call(inValues)[0].Interface().(*Response)
+		}, nil
+	}
 }
 
 func bindParam(in reflect.Type, ctx *gin.Context, queryVals *queryValues) (reflect.Value, error) {
@@ -261,63 +601,33 @@ func bindParam(in reflect.Type, ctx *gin.Context, queryVals *queryValues) (refle
 
 	switch in.Kind() {
 	case reflect.Struct:
-		// bind from url
-		if ctx.Request.ContentLength == 0 {
-			err := ctx.BindQuery(inVal.Interface())
-			if err != nil {
-				return reflect.Value{}, err
-			}
-
-		} else if ctx.ContentType() == ContentTypeJson { // bind json from body
-			err := ctx.BindJSON(inVal.Interface())
-			if err != nil {
-				return reflect.Value{}, err
-			}
-
-		} else {
-			err := ctx.Bind(inVal.Interface())
-			if err != nil {
-				return reflect.Value{}, err
-			}
+		if err := BindStruct(ctx, inVal.Interface()); err != nil {
+			return reflect.Value{}, err
 		}
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 		reflect.String:
-		if !queryVals.inited {
-			if ctx.Request.URL.RawQuery == "" {
-				return reflect.Value{}, errors.New("query is empty")
-			}
-			err := parseQuery(ctx.Request.URL.RawQuery, queryVals)
-			if err != nil {
-				return reflect.Value{}, err
-			}
-		}
-		if queryVals.index >= len(queryVals.keys) {
-			return reflect.Value{}, errors.New("query is empty")
-		}
-		key := queryVals.keys[queryVals.index]
-		queryVals.index++
-		if key == "" {
-			return reflect.Value{}, errors.New("get query key error")
+		v, err := queryVals.next(ctx)
+		if err != nil {
+			return reflect.Value{}, err
 		}
 
-		v := queryVals.kvs[key]
 		switch in.Kind() {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			n, err := strconv.ParseInt(v[0], 10, 64)
+			n, err := strconv.ParseInt(v, 10, 64)
 			if err != nil {
 				return reflect.Value{}, err
 			}
 			inVal.Elem().SetInt(int64(n))
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			n, err := strconv.ParseUint(v[0], 10, 64)
+			n, err := strconv.ParseUint(v, 10, 64)
 			if err != nil {
 				return reflect.Value{}, err
 			}
 			inVal.Elem().SetUint(uint64(n))
 		case reflect.String:
-			inVal.Elem().SetString(v[0])
+			inVal.Elem().SetString(v)
 		}
 	}
 
@@ -335,6 +645,29 @@ type queryValues struct {
 	inited bool
 }
 
+// next return the first value of the next key in the query
+func (queryVals *queryValues) next(ctx *gin.Context) (string, error) {
+	if !queryVals.inited {
+		if ctx.Request.URL.RawQuery == "" {
+			return "", errors.New("query is empty")
+		}
+		err := parseQuery(ctx.Request.URL.RawQuery, queryVals)
+		if err != nil {
+			return "", err
+		}
+	}
+	if queryVals.index >= len(queryVals.keys) {
+		return "", errors.New("query is empty")
+	}
+	key := queryVals.keys[queryVals.index]
+	queryVals.index++
+	if key == "" {
+		return "", errors.New("get query key error")
+	}
+
+	return queryVals.kvs[key][0], nil
+}
+
 func parseQuery(query string, values *queryValues) (err error) {
 	kvsc := strings.Count(query, "&") + 1
 	values.kvs = make(map[string][]string, kvsc)
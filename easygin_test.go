@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"net/http"
 	"net/url"
 	"strconv"
 	"testing"
+	"testing/fstest"
 
 	"github.com/gin-gonic/gin"
 )
@@ -334,6 +336,113 @@ func BenchmarkStructReflectQuery(b *testing.B) {
 	}
 }
 
+func generatedUserHandler(ctx *gin.Context, user *User) *Response {
+	return nil
+}
+
+func generatedQueryHandler(ctx *gin.Context, id int, username, password, email string) *Response {
+	return nil
+}
+
+// the adapters are written in the same way as the code generated by `easygin gen`
+func init() {
+	RegisterAdapter(generatedUserHandler, func(ctx *gin.Context) (func() *Response, error) {
+		p1 := new(User)
+		if err := BindStruct(ctx, p1); err != nil {
+			return nil, err
+		}
+		return func() *Response {
+			return generatedUserHandler(ctx, p1)
+		}, nil
+	})
+
+	RegisterAdapter(generatedQueryHandler, func(ctx *gin.Context) (func() *Response, error) {
+		query := NewQuery(ctx)
+		s1, err := query.Next()
+		if err != nil {
+			return nil, err
+		}
+		n1, err := strconv.ParseInt(s1, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		p1 := int(n1)
+		s2, err := query.Next()
+		if err != nil {
+			return nil, err
+		}
+		p2 := s2
+		s3, err := query.Next()
+		if err != nil {
+			return nil, err
+		}
+		p3 := s3
+		s4, err := query.Next()
+		if err != nil {
+			return nil, err
+		}
+		p4 := s4
+		return func() *Response {
+			return generatedQueryHandler(ctx, p1, p2, p3, p4)
+		}, nil
+	})
+}
+
+// repeatableBody is a request body which can be read repeatedly in the benchmarks
+type repeatableBody struct {
+	b   []byte
+	off int
+}
+
+func (r *repeatableBody) Read(p []byte) (n int, err error) {
+	n = copy(p, r.b[r.off:])
+	r.off += n
+	if r.off == len(r.b) {
+		r.off = 0
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *repeatableBody) Close() error {
+	return nil
+}
+
+// ginRepeatableContext is ginContext whose body can be bound in every iteration
+func ginRepeatableContext() *gin.Context {
+	ctx := ginContext()
+	data, _ := io.ReadAll(io.LimitReader(ctx.Request.Body, ctx.Request.ContentLength))
+	ctx.Request.Body = &repeatableBody{b: data}
+	return ctx
+}
+
+func BenchmarkGeneratedPointer(b *testing.B) {
+	ctx := ginRepeatableContext()
+	f := ginHandlers(generatedUserHandler)[0]
+
+	for i := 0; i < b.N; i++ {
+		f(ctx)
+	}
+}
+
+func BenchmarkGeneratedQuery(b *testing.B) {
+	ctx := ginQueryContext()
+	f := ginHandlers(generatedQueryHandler)[0]
+
+	for i := 0; i < b.N; i++ {
+		f(ctx)
+	}
+}
+
+func BenchmarkStructGeneratedQuery(b *testing.B) {
+	ctx := ginQueryContext()
+	f := ginHandlers(generatedUserHandler)[0]
+
+	for i := 0; i < b.N; i++ {
+		f(ctx)
+	}
+}
+
 type Resp struct {
 	Id   int    `json:"id"`
 	Name string `json:"name"`
@@ -371,3 +480,63 @@ func service() (*Resp, error) {
 		Name: "ape",
 	}, nil
 }
+
+func TestMethods(t *testing.T) {
+	server := New()
+	server.NoRoute(func() *Response {
+		return FailStatus(http.StatusNotFound, RespNotFound)
+	})
+	server.NoMethod(func() *Response {
+		return FailStatus(http.StatusMethodNotAllowed, RespMethodNotAllowed)
+	})
+	server.Any("/any", func(ctx *gin.Context) *Response {
+		return OkData(ctx.Request.Method)
+	})
+	server.OPTIONS("/options", func() *Response {
+		return Ok()
+	})
+	group := server.Group("/api")
+	group.Handle("LINK", "/handle", func(ctx *gin.Context) *Response {
+		return OkData(ctx.Request.Method)
+	})
+	group.Match([]string{http.MethodGet, http.MethodPost}, "/match", func(ctx *gin.Context) *Response {
+		return OkData(ctx.Request.Method)
+	})
+	group.StaticFS("/static", http.FS(fstest.MapFS{"hello.txt": {Data: []byte("hello")}}))
+
+	for _, c := range []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{http.MethodPut, "/any", 200, `{"data":"PUT","code":0,"message":"success"}`},
+		{http.MethodTrace, "/any", 200, `{"data":"TRACE","code":0,"message":"success"}`},
+		{http.MethodOptions, "/options", 200, `{"data":null,"code":0,"message":"success"}`},
+		{"LINK", "/api/handle", 200, `{"data":"LINK","code":0,"message":"success"}`},
+		{http.MethodPost, "/api/match", 200, `{"data":"POST","code":0,"message":"success"}`},
+		{http.MethodDelete, "/api/match", 405, `{"data":null,"code":405,"message":"method not allowed"}`},
+		{http.MethodGet, "/api/static/hello.txt", 200, "hello"},
+		{http.MethodGet, "/api/static/missing.txt", 404, `{"data":null,"code":404,"message":"not found"}`},
+		{http.MethodGet, "/missing", 404, `{"data":null,"code":404,"message":"not found"}`},
+	} {
+		t.Run(c.method+" "+c.path, func(t *testing.T) {
+			w := serveRequest(server, c.method, c.path, nil)
+			if w.Code != c.status || w.Body.String() != c.body {
+				t.Errorf("expect %d %s, got %d %s", c.status, c.body, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	methods := make(map[string][]string)
+	for _, info := range server.RegisteredRoutes() {
+		methods[info.Path] = append(methods[info.Path], info.Method)
+	}
+	for p, n := range map[string]int{"/any": len(anyMethods), "/options": 1, "/api/handle": 1, "/api/match": 2, "/api/static/*filepath": 2} {
+		if len(methods[p]) != n {
+			t.Errorf("expect %d methods of %s registered, got %v", n, p, methods[p])
+		}
+	}
+	if doc := server.OpenAPI(OpenAPIConfig{}); doc.Paths["/any"]["connect"] != nil || doc.Paths["/any"]["trace"] == nil {
+		t.Errorf("unexpected operations of /any: %v", doc.Paths["/any"])
+	}
+}
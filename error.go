@@ -1,6 +1,9 @@
 package easygin
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 type RespError interface {
 	error
@@ -14,12 +17,29 @@ type RespError interface {
 type RespErrorImpl struct {
 	Codee    int    `json:"code"`
 	Messagee string `json:"message"`
+
+	// cause and requestID are only set by NewFromError and NewFromErrorContext
+	cause     error
+	requestID string
 }
 
 func (e *RespErrorImpl) Error() string {
+	if e.requestID != "" {
+		return fmt.Sprintf("[%d]%s (request_id=%s)", e.Codee, e.Messagee, e.requestID)
+	}
 	return fmt.Sprintf("[%d]%s", e.Codee, e.Messagee)
 }
 
+// Unwrap return the error passed to NewFromError
+func (e *RespErrorImpl) Unwrap() error {
+	return e.cause
+}
+
+// RequestID return the id of the request in which the error is produced
+func (e *RespErrorImpl) RequestID() string {
+	return e.requestID
+}
+
 func (e *RespErrorImpl) Message() string {
 	return e.Messagee
 }
@@ -42,11 +62,36 @@ func NewErrorf(code int, format string, args ...interface{}) RespError {
 	}
 }
 
+// NewFromError create a RespError from err, when returned by a handler,
+// the id of the current request is attached to it
 func NewFromError(err error) RespError {
 	return &RespErrorImpl{
 		Codee:    UnknownErrorCode,
 		Messagee: err.Error(),
+		cause:    err,
+	}
+}
+
+// NewFromErrorContext is like NewFromError but attaches the request id carried by ctx immediately
+func NewFromErrorContext(ctx context.Context, err error) RespError {
+	return &RespErrorImpl{
+		Codee:     UnknownErrorCode,
+		Messagee:  err.Error(),
+		cause:     err,
+		requestID: RequestIDFromContext(ctx),
+	}
+}
+
+// attachRequestID return a copy of the error created by NewFromError with id attached,
+// the error itself is not modified since it may be shared by the concurrent requests
+func attachRequestID(err RespError, id string) RespError {
+	e, ok := err.(*RespErrorImpl)
+	if !ok || e.cause == nil || e.requestID != "" {
+		return err
 	}
+	attached := *e
+	attached.requestID = id
+	return &attached
 }
 
 func IsRespError(err error) bool {
@@ -73,8 +118,10 @@ func IsSuccess(err RespError) bool {
 }
 
 const (
-	UnknownErrorCode = -1
-	SuccessCode      = 0
+	UnknownErrorCode     = -1
+	SuccessCode          = 0
+	NotFoundCode         = 404
+	MethodNotAllowedCode = 405
 )
 
 var (
@@ -82,4 +129,13 @@ var (
 		Codee:    SuccessCode,
 		Messagee: "success",
 	})
+	// RespNotFound and RespMethodNotAllowed can be returned by the handlers of EasyGin.NoRoute and EasyGin.NoMethod
+	RespNotFound = RespError(&RespErrorImpl{
+		Codee:    NotFoundCode,
+		Messagee: "not found",
+	})
+	RespMethodNotAllowed = RespError(&RespErrorImpl{
+		Codee:    MethodNotAllowedCode,
+		Messagee: "method not allowed",
+	})
 )
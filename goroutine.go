@@ -4,7 +4,9 @@
 package easygin
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path"
 	"runtime/pprof"
@@ -17,16 +19,16 @@ const (
 	debugLevel       = 2
 )
 
-func dumpGoroutines() {
+func dumpGoroutines(logger Logger) {
 	command := path.Base(os.Args[0])
 	pid := syscall.Getpid()
 	dumpFile := path.Join(os.TempDir(), fmt.Sprintf("%s-%d-goroutines-%s.dump",
 		command, pid, time.Now().Format(timeFormat)))
 
-	elog.Printf("Got dump goroutine signal, printing goroutine profile to %s", dumpFile)
+	logger.Log(context.Background(), slog.LevelInfo, "got dump goroutine signal, printing goroutine profile", "file", dumpFile)
 
 	if f, err := os.Create(dumpFile); err != nil {
-		elog.Printf("Failed to dump goroutine profile, error: %v", err)
+		logger.Log(context.Background(), slog.LevelError, "failed to dump goroutine profile", "error", err)
 	} else {
 		defer f.Close()
 		pprof.Lookup(goroutineProfile).WriteTo(f, debugLevel)
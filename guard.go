@@ -0,0 +1,75 @@
+package easygin
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// guard checks the request before the handler is called, it returns the Response rejecting the request or nil.
+// guards with afterBind are checked after the parameters are bound, so they can use BoundRequest
+type guard struct {
+	afterBind bool
+	// authentication is true for the guards of Auth, the principals are injected on their routes
+	authentication bool
+	// authorization describes the authorization checked by the guard in the route introspection
+	authorization string
+	check         func(ctx *gin.Context) *Response
+}
+
+// runGuards run the guards of the phase in order, it returns the first rejection
+func runGuards(ctx *gin.Context, guards []guard, afterBind bool) *Response {
+	for _, g := range guards {
+		if g.afterBind != afterBind {
+			continue
+		}
+		if resp := g.check(ctx); resp != nil {
+			return resp
+		}
+	}
+	return nil
+}
+
+// orderGuards return the guards with the authorization guards declared before Auth moved after the last
+// authentication guard, etc.: Authorize of a group and Auth of the route, so the policies see the principal.
+// the others keep their order, etc.: RateLimit declared before Auth limits the failed authentications
+func orderGuards(guards []guard) []guard {
+	last := -1
+	for i, g := range guards {
+		if g.authentication {
+			last = i
+		}
+	}
+	ordered := make([]guard, 0, len(guards))
+	var moved []guard
+	for i, g := range guards {
+		if i < last && g.authorization != "" {
+			moved = append(moved, g)
+			continue
+		}
+		ordered = append(ordered, g)
+		if i == last {
+			ordered = append(ordered, moved...)
+		}
+	}
+	return ordered
+}
+
+// authenticated report whether the guards authenticate the requests
+func authenticated(guards []guard) bool {
+	for _, g := range guards {
+		if g.authentication {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizations return the descriptions of the authorization guards
+func authorizations(guards []guard) []string {
+	var descriptions []string
+	for _, g := range guards {
+		if g.authorization != "" {
+			descriptions = append(descriptions, g.authorization)
+		}
+	}
+	return descriptions
+}
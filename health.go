@@ -0,0 +1,160 @@
+package easygin
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const UnavailableCode = 503
+
+var RespUnavailable = RespError(&RespErrorImpl{
+	Codee:    UnavailableCode,
+	Messagee: "service unavailable",
+})
+
+const (
+	defaultHealthCheckTimeout = time.Second
+	healthOK                  = "ok"
+	healthShuttingDown        = "shutting down"
+)
+
+type HealthConfig struct {
+	// LivenessPath is the path of the liveness endpoint, default is /healthz
+	LivenessPath string
+	// ReadinessPath is the path of the readiness endpoint, default is /readyz
+	ReadinessPath string
+	// Timeout is the default timeout of the checks, default is 1s
+	Timeout time.Duration
+	// PreShutdownDelay is the delay between the readiness failing and draining the servers at shutdown,
+	// so that the load balancers stop routing to the server, etc.: the update of the Kubernetes endpoints
+	PreShutdownDelay time.Duration
+}
+
+// HealthCheck checks whether the server is healthy, it returns an error if it is not
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+	// Timeout is the timeout of the check, default is the Timeout of HealthConfig
+	Timeout time.Duration
+	// Liveness is true if the check is also checked by the liveness endpoint,
+	// otherwise it is only checked by the readiness endpoint, etc.: the connection of the database
+	Liveness bool
+}
+
+// health keeps the health checks of EasyGin
+type health struct {
+	mu     sync.RWMutex
+	config HealthConfig
+	checks []HealthCheck
+}
+
+// ServeHealth register the liveness and readiness endpoints, they respond 200 with the results of the checks
+// if all the checks pass, otherwise 503. the readiness fails as soon as the server starts to shut down
+func (e *EasyGin) ServeHealth(config HealthConfig) {
+	if config.LivenessPath == "" {
+		config.LivenessPath = "/healthz"
+	}
+	if config.ReadinessPath == "" {
+		config.ReadinessPath = "/readyz"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultHealthCheckTimeout
+	}
+	e.health.mu.Lock()
+	e.health.config = config
+	e.health.mu.Unlock()
+
+	e.Engine.GET(config.LivenessPath, func(ctx *gin.Context) {
+		e.respondHealth(ctx, true)
+	})
+	e.Engine.GET(config.ReadinessPath, func(ctx *gin.Context) {
+		e.respondHealth(ctx, false)
+	})
+}
+
+// AddHealthCheck add the checks of the health endpoints
+func (e *EasyGin) AddHealthCheck(checks ...HealthCheck) {
+	e.health.mu.Lock()
+	defer e.health.mu.Unlock()
+	e.health.checks = append(e.health.checks, checks...)
+}
+
+func (e *EasyGin) respondHealth(ctx *gin.Context, liveness bool) {
+	e.health.mu.RLock()
+	config := e.health.config
+	var checks []HealthCheck
+	for _, check := range e.health.checks {
+		if check.Liveness || !liveness {
+			checks = append(checks, check)
+		}
+	}
+	e.health.mu.RUnlock()
+
+	results := make(map[string]string, len(checks)+1)
+	healthy := true
+	if !liveness && e.shuttingDown.Load() {
+		results["shutdown"] = healthShuttingDown
+		healthy = false
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+			err := runHealthCheck(ctx.Request.Context(), check, config.Timeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				results[check.Name] = err.Error()
+				healthy = false
+			} else {
+				results[check.Name] = healthOK
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	var resp *Response
+	if healthy {
+		resp = OkData(results)
+	} else {
+		resp = NewResponse(http.StatusServiceUnavailable, results, RespUnavailable)
+	}
+	ctx.JSON(resp.Status, &resp.R)
+	pool.Put(resp)
+}
+
+// runHealthCheck call the check with its timeout, it returns the error of the context if the check does not return in time
+func runHealthCheck(ctx context.Context, check HealthCheck, timeout time.Duration) error {
+	if check.Timeout > 0 {
+		timeout = check.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Check(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// preShutdownDelay return the PreShutdownDelay of the health endpoints
+func (e *EasyGin) preShutdownDelay() time.Duration {
+	e.health.mu.RLock()
+	defer e.health.mu.RUnlock()
+	return e.health.config.PreShutdownDelay
+}
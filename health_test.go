@@ -0,0 +1,85 @@
+package easygin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := New()
+	server.SetSignalHandler(func() context.Context { return ctx })
+	server.ServeHealth(HealthConfig{PreShutdownDelay: 200 * time.Millisecond})
+
+	var dbErr atomic.Value
+	dbErr.Store("")
+	server.AddHealthCheck(
+		HealthCheck{Name: "goroutines", Liveness: true, Check: func(ctx context.Context) error {
+			return nil
+		}},
+		HealthCheck{Name: "db", Check: func(ctx context.Context) error {
+			if msg := dbErr.Load().(string); msg != "" {
+				return errors.New(msg)
+			}
+			return nil
+		}},
+	)
+
+	l, serveErr := startServer(t, server)
+	get := func(path string) (int, string) {
+		return httpGet(http.DefaultClient, "http://"+l.Addr().String()+path)
+	}
+
+	tests := []struct {
+		dbErr  string
+		path   string
+		status int
+		body   string
+	}{
+		{"", "/healthz", 200, `{"data":{"goroutines":"ok"},"code":0,"message":"success"}`},
+		{"", "/readyz", 200, `{"data":{"db":"ok","goroutines":"ok"},"code":0,"message":"success"}`},
+		{"connection refused", "/healthz", 200, `{"data":{"goroutines":"ok"},"code":0,"message":"success"}`},
+		{"connection refused", "/readyz", 503, `{"data":{"db":"connection refused","goroutines":"ok"},"code":503,"message":"service unavailable"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.path+" "+test.dbErr, func(t *testing.T) {
+			dbErr.Store(test.dbErr)
+			if status, body := get(test.path); status != test.status || body != test.body {
+				t.Errorf("expect %d %s, got %d %s", test.status, test.body, status, body)
+			}
+		})
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		// the check is failed after its timeout
+		dbErr.Store("")
+		server.AddHealthCheck(HealthCheck{Name: "slow", Timeout: 10 * time.Millisecond, Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}})
+		if status, body := get("/readyz"); status != 503 || !strings.Contains(body, `"slow":"context deadline exceeded"`) {
+			t.Errorf("expect the slow check to time out, got %d %s", status, body)
+		}
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		// the readiness fails during the pre-shutdown delay while the server is still serving
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+		if status, body := get("/readyz"); status != 503 || !strings.Contains(body, `"shutdown":"shutting down"`) {
+			t.Errorf("expect the readiness to fail, got %d %s", status, body)
+		}
+		if status, _ := get("/healthz"); status != 200 {
+			t.Errorf("expect the liveness to pass, got %d", status)
+		}
+		if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("expect ErrServerClosed, got %v", err)
+		}
+	})
+}
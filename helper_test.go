@@ -0,0 +1,82 @@
+package easygin
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// serveRequest serve the request by handler and return the recorder, header is the pairs of the header names and values
+func serveRequest(handler http.Handler, method, path string, body io.Reader, header ...string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, body)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// httpGet send a GET request by client and return the status and body, the error is returned as the body
+func httpGet(client *http.Client, url string) (int, string) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+// startServer serve server on a random local port, the error of Serve is sent to the returned channel
+func startServer(t *testing.T, server *EasyGin) (net.Listener, <-chan error) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(l)
+	}()
+	return l, serveErr
+}
+
+// waitServing wait until the server listening on addr responds /ping
+func waitServing(t *testing.T, addr string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if status, _ := httpGet(http.DefaultClient, "http://"+addr+"/ping"); status != 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("server %s is not serving", addr)
+}
+
+// blocker blocks a handler until it is released, to test the draining of the in-flight requests
+type blocker struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlocker() *blocker {
+	return &blocker{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+// wait is called by the handler, it returns after release
+func (b *blocker) wait() {
+	close(b.started)
+	<-b.release
+}
+
+// drain release the handler after the server starts to shut down
+func (b *blocker) drain(shutdown func()) {
+	<-b.started
+	shutdown()
+	time.Sleep(50 * time.Millisecond)
+	close(b.release)
+}
@@ -0,0 +1,43 @@
+package easygin
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// boundRequestKey is the key of the struct bound by BindStruct in gin.Context
+const boundRequestKey = "easygin/request"
+
+// Interceptor is called with the Response of the typed handler before it is rendered,
+// req is the bound request returned by BoundRequest. it returns the Response to render,
+// which can be resp itself after modifying it, another Response, or nil to render nothing.
+// interceptors can be used for field masking, audit logging, data transformation and adding envelope metadata
+type Interceptor func(ctx *gin.Context, req interface{}, resp *Response) *Response
+
+// Intercept add interceptors to the group, they are called in order for the routes registered after Intercept,
+// the interceptors of the parent group are called first
+func (r *RouterGroup) Intercept(interceptors ...Interceptor) {
+	r.interceptors = append(r.interceptors, interceptors...)
+}
+
+// Intercept add interceptors to all the routes registered after it, see RouterGroup.Intercept
+func (e *EasyGin) Intercept(interceptors ...Interceptor) {
+	e.root.Intercept(interceptors...)
+}
+
+// BoundRequest return the pointer of the struct bound as the parameter of the handler,
+// if the handler has several struct parameters, the last one is returned, it returns nil if there is none
+func BoundRequest(ctx *gin.Context) interface{} {
+	req, _ := ctx.Get(boundRequestKey)
+	return req
+}
+
+// runInterceptors call the interceptors in order, it stops if an interceptor returns nil
+func runInterceptors(ctx *gin.Context, interceptors []Interceptor, resp *Response) *Response {
+	req := BoundRequest(ctx)
+	for _, interceptor := range interceptors {
+		if resp = interceptor(ctx, req, resp); resp == nil {
+			return nil
+		}
+	}
+	return resp
+}
@@ -0,0 +1,58 @@
+package easygin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestInterceptor(t *testing.T) {
+	type Account struct {
+		Name     string `json:"name" form:"name"`
+		Password string `json:"password" form:"password"`
+	}
+
+	var audit []string
+	server := New()
+	server.Intercept(func(ctx *gin.Context, req interface{}, resp *Response) *Response {
+		if account, ok := req.(*Account); ok {
+			audit = append(audit, account.Name)
+		}
+		return resp
+	})
+	group := server.Group("/account")
+	group.Intercept(func(ctx *gin.Context, req interface{}, resp *Response) *Response {
+		if account, ok := resp.R.Data.(*Account); ok {
+			masked := *account
+			masked.Password = "***"
+			resp.R.Data = &masked
+		}
+		return resp.SetMeta("version", 1)
+	})
+	group.POST("", func(account *Account) *Response {
+		return OkData(account)
+	})
+	server.GET("/ping", func() *Response {
+		return Ok()
+	})
+
+	tests := []struct {
+		method, path string
+		body         string
+	}{
+		{http.MethodPost, "/account?name=ape&password=123", `{"data":{"name":"ape","password":"***"},"code":0,"message":"success","meta":{"version":1}}`},
+		{http.MethodGet, "/ping", `{"data":null,"code":0,"message":"success"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			if w := serveRequest(server, test.method, test.path, nil); w.Body.String() != test.body {
+				t.Errorf("expect %s, got %s", test.body, w.Body.String())
+			}
+		})
+	}
+	if strings.Join(audit, ",") != "ape" {
+		t.Errorf("unexpected audit log: %v", audit)
+	}
+}
@@ -0,0 +1,247 @@
+package easygin
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var RespTokenExpired = RespError(&RespErrorImpl{
+	Codee:    UnauthorizedCode,
+	Messagee: "token expired",
+})
+
+type JWTConfig struct {
+	// Secret verifies the HS256, HS384 and HS512 tokens
+	Secret []byte
+	// JWKSFile is a local JSON Web Key Set file, its RSA keys verify the RS256, RS384 and RS512 tokens,
+	// its oct keys verify the HS tokens, the key is selected by the kid of the token
+	JWKSFile string
+	// Issuer and Audience are verified if they are not empty
+	Issuer   string
+	Audience string
+	// Leeway tolerates the clock skew when checking exp and nbf
+	Leeway time.Duration
+}
+
+type jwtAuthenticator[T any] struct {
+	config   JWTConfig
+	hmacKeys map[string][]byte
+	rsaKeys  map[string]*rsa.PublicKey
+}
+
+// JWT authenticate the requests by the bearer token in the Authorization header,
+// the claims of the token are decoded into *T which is the principal
+func JWT[T any](config JWTConfig) (Authenticator, error) {
+	a := &jwtAuthenticator[T]{
+		config:   config,
+		hmacKeys: make(map[string][]byte),
+		rsaKeys:  make(map[string]*rsa.PublicKey),
+	}
+	if config.JWKSFile != "" {
+		if err := a.loadJWKS(config.JWKSFile); err != nil {
+			return nil, err
+		}
+	}
+	if len(config.Secret) == 0 && len(a.hmacKeys) == 0 && len(a.rsaKeys) == 0 {
+		return nil, errors.New("jwt: no key is configured")
+	}
+	RegisterPrincipal[T]()
+	return a, nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	// N and E are the modulus and exponent of RSA keys
+	N string `json:"n"`
+	E string `json:"e"`
+	// K is the value of oct keys
+	K string `json:"k"`
+}
+
+func (a *jwtAuthenticator[T]) loadJWKS(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err = json.Unmarshal(data, &jwks); err != nil {
+		return fmt.Errorf("jwt: parse jwks %s: %w", file, err)
+	}
+
+	for _, key := range jwks.Keys {
+		switch key.Kty {
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(key.N)
+			if err != nil {
+				return fmt.Errorf("jwt: invalid modulus of key %q: %w", key.Kid, err)
+			}
+			e, err := base64.RawURLEncoding.DecodeString(key.E)
+			if err != nil {
+				return fmt.Errorf("jwt: invalid exponent of key %q: %w", key.Kid, err)
+			}
+			a.rsaKeys[key.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "oct":
+			k, err := base64.RawURLEncoding.DecodeString(key.K)
+			if err != nil {
+				return fmt.Errorf("jwt: invalid value of key %q: %w", key.Kid, err)
+			}
+			a.hmacKeys[key.Kid] = k
+		}
+	}
+	return nil
+}
+
+func (a *jwtAuthenticator[T]) challenge() string {
+	return "Bearer"
+}
+
+func (a *jwtAuthenticator[T]) Authenticate(ctx *gin.Context) (interface{}, error) {
+	auth := ctx.GetHeader("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+		return nil, ErrNoCredentials
+	}
+	parts := strings.Split(strings.TrimSpace(auth[7:]), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("jwt: malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("jwt: malformed signature: %w", err)
+	}
+	if err = a.verify(header.Alg, header.Kid, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims struct {
+		Exp *float64 `json:"exp"`
+		Nbf *float64 `json:"nbf"`
+		Iss string   `json:"iss"`
+		Aud audience `json:"aud"`
+	}
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if claims.Exp != nil && now.After(unixTime(*claims.Exp).Add(a.config.Leeway)) {
+		return nil, RespTokenExpired
+	}
+	if claims.Nbf != nil && now.Add(a.config.Leeway).Before(unixTime(*claims.Nbf)) {
+		return nil, errors.New("jwt: token is not valid yet")
+	}
+	if a.config.Issuer != "" && claims.Iss != a.config.Issuer {
+		return nil, errors.New("jwt: invalid issuer")
+	}
+	if a.config.Audience != "" && !claims.Aud.contains(a.config.Audience) {
+		return nil, errors.New("jwt: invalid audience")
+	}
+
+	principal := new(T)
+	if err = decodeSegment(parts[1], principal); err != nil {
+		return nil, err
+	}
+	return principal, nil
+}
+
+// verify the signature by the alg, the key is selected by the type required by the alg,
+// so a token can not be verified by a key of another type
+func (a *jwtAuthenticator[T]) verify(alg, kid, signingInput string, signature []byte) error {
+	var hash crypto.Hash
+	switch alg[min(len(alg), 2):] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("jwt: unsupported alg %q", alg)
+	}
+
+	switch {
+	case strings.HasPrefix(alg, "HS"):
+		key, ok := a.hmacKeys[kid]
+		if !ok {
+			if key = a.config.Secret; len(key) == 0 {
+				return fmt.Errorf("jwt: no hmac key %q", kid)
+			}
+		}
+		mac := hmac.New(hash.New, key)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("jwt: invalid signature")
+		}
+		return nil
+	case strings.HasPrefix(alg, "RS"):
+		key, ok := a.rsaKeys[kid]
+		if !ok {
+			return fmt.Errorf("jwt: no rsa key %q", kid)
+		}
+		h := hash.New()
+		h.Write([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(key, hash, h.Sum(nil), signature); err != nil {
+			return errors.New("jwt: invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("jwt: unsupported alg %q", alg)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("jwt: malformed segment: %w", err)
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("jwt: malformed segment: %w", err)
+	}
+	return nil
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// audience is the aud claim, which is a string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+func (a audience) contains(s string) bool {
+	for _, aud := range a {
+		if aud == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package easygin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Phase is when the shutdown hook is called
+type Phase int
+
+const (
+	// BeforeDrain hooks are called before the servers are shut down, the requests are still served
+	BeforeDrain Phase = iota
+	// AfterDrain hooks are called after the servers are drained, etc.: closing the database
+	AfterDrain
+)
+
+func (p Phase) String() string {
+	if p == BeforeDrain {
+		return "before-drain"
+	}
+	return "after-drain"
+}
+
+// ShutdownHook is called when the server shuts down
+type ShutdownHook struct {
+	Name  string
+	Phase Phase
+	// Priority orders the hooks of the phase, hooks of higher priority are called first,
+	// hooks of the same priority are called in the order they are added
+	Priority int
+	// Timeout is the timeout of the hook, the shutdown does not wait for the hook after it,
+	// default is the rest of maxGraceDuration of the phase
+	Timeout time.Duration
+	Hook    func(ctx context.Context) error
+}
+
+// Lifecycle manages the shutdown hooks of EasyGin, they are called whichever signal handler is set
+type Lifecycle struct {
+	mu     sync.Mutex
+	hooks  []ShutdownHook
+	logger func() Logger
+}
+
+// Add add the shutdown hooks
+func (l *Lifecycle) Add(hooks ...ShutdownHook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, hooks...)
+}
+
+// run call the hooks of the phase in order, ctx is the context of the phase,
+// the errors of the hooks are joined
+func (l *Lifecycle) run(ctx context.Context, phase Phase) error {
+	l.mu.Lock()
+	var hooks []ShutdownHook
+	for _, hook := range l.hooks {
+		if hook.Phase == phase {
+			hooks = append(hooks, hook)
+		}
+	}
+	l.mu.Unlock()
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return hooks[i].Priority > hooks[j].Priority
+	})
+
+	var errs []error
+	for _, hook := range hooks {
+		start := time.Now()
+		if err := runHook(ctx, hook); err != nil {
+			l.logger().Log(ctx, slog.LevelError, "shutdown hook failed", "hook", hook.Name, "phase", phase, "error", err)
+			errs = append(errs, fmt.Errorf("shutdown hook %s: %w", hook.Name, err))
+			continue
+		}
+		l.logger().Log(ctx, slog.LevelDebug, "shutdown hook finished", "hook", hook.Name, "phase", phase, "duration", time.Since(start))
+	}
+	return errors.Join(errs...)
+}
+
+// runHook call the hook with its timeout, it returns the error of the context if the hook does not return in time
+func runHook(ctx context.Context, hook ShutdownHook) error {
+	if hook.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hook.Timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- hook.Hook(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Lifecycle return the lifecycle manager of the shutdown hooks
+func (e *EasyGin) Lifecycle() *Lifecycle {
+	return &e.lifecycle
+}
+
+// OnShutdown add a shutdown hook of the phase with the default priority and timeout, see Lifecycle
+func (e *EasyGin) OnShutdown(name string, phase Phase, hook func(ctx context.Context) error) {
+	e.lifecycle.Add(ShutdownHook{Name: name, Phase: phase, Hook: hook})
+}
+
+// Shutdown shut down the server gracefully as the signals do, it returns the errors of the draining and the hooks
+// after the servers are drained and the shutdown hooks are called, or the error of ctx if it is done before.
+// the shutdown is run by the serving goroutine, if the server is not served yet, it shuts down once served
+func (e *EasyGin) Shutdown(ctx context.Context) error {
+	e.startShutdown()
+	select {
+	case <-e.drained:
+		return e.shutdownErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startShutdown notify the signal goroutine to shut down
+func (e *EasyGin) startShutdown() {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+	})
+}
+
+// runShutdown shut down once after the servers are set by serve, drained is closed after it finishes
+func (e *EasyGin) runShutdown() {
+	e.shutdownOnce.Do(func() {
+		e.shutdownErr = e.shutdown()
+		close(e.drained)
+	})
+}
+
+// shutdown fail the readiness and wait for the PreShutdownDelay of the health endpoints, then call the BeforeDrain
+// hooks and shut down the servers gracefully within maxGraceDuration, and call the AfterDrain hooks within
+// another maxGraceDuration, so they are called even if the draining times out. the errors are joined
+func (e *EasyGin) shutdown() error {
+	e.Logger().Log(context.Background(), slog.LevelInfo, "shutting down server", "addrs", e.Addrs())
+	e.shuttingDown.Store(true)
+	if delay := e.preShutdownDelay(); delay > 0 {
+		time.Sleep(delay)
+	}
+
+	// one deadline for the whole shutdown, the BeforeDrain hooks and the draining leave afterDrainReserve of it
+	// to the AfterDrain hooks
+	ctx, cancelFunc := e.graceContext()
+	defer cancelFunc()
+	drainCtx, cancelDrain := e.drainContext(ctx)
+	beforeErr := e.lifecycle.run(drainCtx, BeforeDrain)
+	start := time.Now()
+	drainErr := e.shutdownServers(drainCtx)
+	cancelDrain()
+	if drainErr != nil {
+		e.Logger().Log(context.Background(), slog.LevelError, "an error occurs when server shut", "error", drainErr)
+	}
+	if e.metrics != nil {
+		e.metrics.observeShutdown(time.Since(start))
+	}
+
+	afterErr := e.lifecycle.run(ctx, AfterDrain)
+	return errors.Join(beforeErr, drainErr, afterErr)
+}
+
+// graceContext return the context of the shutdown, it has no deadline if maxGraceDuration is 0
+func (e *EasyGin) graceContext() (context.Context, context.CancelFunc) {
+	if e.maxGraceDuration <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), e.maxGraceDuration)
+}
+
+// drainContext return the context of the BeforeDrain hooks and the draining, it ends afterDrainReserve
+// before the deadline of ctx, the reserve is at most half of maxGraceDuration
+func (e *EasyGin) drainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || e.afterDrainReserve <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline.Add(-min(e.afterDrainReserve, e.maxGraceDuration/2)))
+}
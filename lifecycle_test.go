@@ -0,0 +1,137 @@
+package easygin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestShutdownHooks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := newBlocker()
+	server := New()
+	server.SetMaxGraceDuration(5 * time.Second)
+	server.SetSignalHandler(func() context.Context { return ctx })
+	server.GET("/slow", func() *Response {
+		b.wait()
+		return Ok()
+	})
+
+	var (
+		mu     sync.Mutex
+		called []string
+	)
+	hook := func(name string, err error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			called = append(called, name)
+			mu.Unlock()
+			return err
+		}
+	}
+	errDB := errors.New("close db failed")
+	server.OnShutdown("deregister", BeforeDrain, hook("deregister", nil))
+	server.SetAfterCloseHandlers(func() {
+		_ = hook("afterClose", nil)(context.Background())
+	})
+	server.Lifecycle().Add(
+		ShutdownHook{Name: "db", Phase: AfterDrain, Priority: -1, Hook: hook("db", errDB)},
+		ShutdownHook{Name: "flush", Phase: AfterDrain, Priority: 1, Hook: hook("flush", nil)},
+		ShutdownHook{Name: "stuck", Phase: BeforeDrain, Timeout: 10 * time.Millisecond, Hook: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}},
+		ShutdownHook{Name: "drained", Phase: AfterDrain, Priority: 2, Hook: func(ctx context.Context) error {
+			select {
+			case <-b.release:
+				return hook("drained", nil)(ctx)
+			default:
+				return errors.New("called before the request is drained")
+			}
+		}},
+	)
+
+	l, serveErr := startServer(t, server)
+	go httpGet(http.DefaultClient, "http://"+l.Addr().String()+"/slow")
+	b.drain(cancel)
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("expect ErrServerClosed, got %v", err)
+	}
+	if expect := []string{"deregister", "drained", "flush", "afterClose", "db"}; fmt.Sprint(called) != fmt.Sprint(expect) {
+		t.Errorf("expect hooks %v, got %v", expect, called)
+	}
+
+	// the errors of the hooks are joined
+	err := server.shutdown()
+	if !errors.Is(err, errDB) || !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "shutdown hook stuck") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestShutdownGraceDuration(t *testing.T) {
+	t.Run("engine", func(t *testing.T) {
+		// the hooks of the server created with an engine are waited for
+		server := NewWithEngine(gin.New())
+		closed := false
+		server.SetAfterCloseHandlers(func() {
+			time.Sleep(10 * time.Millisecond)
+			closed = true
+		})
+		if err := server.shutdown(); err != nil || !closed {
+			t.Errorf("expect the after close handler to be called, got %v", err)
+		}
+	})
+
+	t.Run("after drain", func(t *testing.T) {
+		// the AfterDrain hooks are called with the reserve after the BeforeDrain hooks use up the rest,
+		// the whole shutdown has one deadline
+		server := New()
+		server.SetMaxGraceDuration(40 * time.Millisecond)
+		server.SetAfterDrainReserve(10 * time.Millisecond)
+		var afterDeadline time.Time
+		// the hook may still run when its context is done, the deadline is passed by a channel
+		deadlines := make(chan time.Time, 1)
+		server.OnShutdown("slow", BeforeDrain, func(ctx context.Context) error {
+			deadline, _ := ctx.Deadline()
+			deadlines <- deadline
+			<-ctx.Done()
+			return nil
+		})
+		closed := false
+		server.OnShutdown("close", AfterDrain, func(ctx context.Context) error {
+			afterDeadline, _ = ctx.Deadline()
+			closed = ctx.Err() == nil
+			return nil
+		})
+		start := time.Now()
+		_ = server.shutdown()
+		if !closed {
+			t.Error("expect the AfterDrain hook to be called before its deadline")
+		}
+		beforeDeadline := <-deadlines
+		if afterDeadline.Sub(beforeDeadline) != 10*time.Millisecond || afterDeadline.After(start.Add(50*time.Millisecond)) {
+			t.Errorf("expect one deadline with the reserve, got %v and %v", beforeDeadline.Sub(start), afterDeadline.Sub(start))
+		}
+	})
+
+	t.Run("no deadline", func(t *testing.T) {
+		server := New()
+		server.SetMaxGraceDuration(0)
+		server.OnShutdown("close", AfterDrain, func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); ok {
+				return errors.New("unexpected deadline")
+			}
+			return nil
+		})
+		if err := server.shutdown(); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,260 @@
+package easygin
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// ListenConfig describes a listener of the server
+type ListenConfig struct {
+	// Network is tcp, tcp4, tcp6 or unix, default is tcp
+	Network string
+	// Addr is the address of tcp, or the path of the unix socket
+	Addr string
+	// Mode is the permission of the unix socket file, default is the umask of the process
+	Mode os.FileMode
+	// TLS serves HTTPS on the listener if it is set
+	TLS *TLSConfig
+	// Handler handles the requests of the listener instead of EasyGin, etc.: the handler of the admin port
+	Handler http.Handler
+}
+
+// serverListener is a listener and the server serving it, listeners without a handler share EasyGin.Server
+type serverListener struct {
+	net.Listener
+	// raw is the listener of the socket before wrapped by TLS, it is passed to the new process by the graceful restart
+	raw    net.Listener
+	server *http.Server
+}
+
+// Listen open a listener which is served with the other listeners by Serve, ListenAndServe or ListenAndServeTLS,
+// all of them are shut down gracefully together. the listener passed by systemd socket activation or
+// the graceful restart with the same address is used if there is one. a stale unix socket file, which refuses
+// the connections, is removed before listening, the socket of a live server is not
+func (e *EasyGin) Listen(config ListenConfig) error {
+	if config.Network == "" {
+		config.Network = "tcp"
+	}
+	if l := takeInherited(config.Network, config.Addr); l != nil {
+		return e.listen(l, config)
+	}
+
+	if config.Network == "unix" {
+		if info, err := os.Stat(config.Addr); err == nil && info.Mode()&os.ModeSocket != 0 {
+			conn, err := net.Dial("unix", config.Addr)
+			switch {
+			case err == nil:
+				_ = conn.Close()
+				return fmt.Errorf("unix socket %s is in use", config.Addr)
+			case errors.Is(err, syscall.ECONNREFUSED):
+				_ = os.Remove(config.Addr)
+			}
+		}
+	}
+	l, err := net.Listen(config.Network, config.Addr)
+	if err != nil {
+		return err
+	}
+	if config.Network == "unix" && config.Mode != 0 {
+		if err = os.Chmod(config.Addr, config.Mode); err != nil {
+			_ = l.Close()
+			return fmt.Errorf("chmod unix socket: %w", err)
+		}
+	}
+	return e.listen(l, config)
+}
+
+// ListenInherited add the listeners passed by systemd socket activation or the graceful restart
+// which are not used by Listen, it should be called after Listen. they are served by EasyGin without TLS
+func (e *EasyGin) ListenInherited() {
+	for _, l := range takeAllInherited() {
+		e.addListener(l, l, nil)
+	}
+}
+
+// listen add the listener opened by Listen
+func (e *EasyGin) listen(l net.Listener, config ListenConfig) error {
+	raw := l
+	if config.TLS != nil {
+		tlsConfig, err := e.newTLSConfig(*config.TLS)
+		if err != nil {
+			_ = l.Close()
+			return err
+		}
+		tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+		l = tls.NewListener(l, tlsConfig)
+	}
+	e.addListener(l, raw, config.Handler)
+	return nil
+}
+
+func (e *EasyGin) addListener(l, raw net.Listener, handler http.Handler) {
+	sl := &serverListener{Listener: l, raw: raw}
+	if handler != nil {
+		sl.server = &http.Server{Handler: handler}
+	}
+	e.listenersMu.Lock()
+	e.listeners = append(e.listeners, sl)
+	e.listenersMu.Unlock()
+}
+
+// listenerList return a snapshot of the listeners, they may be added while serving
+func (e *EasyGin) listenerList() []*serverListener {
+	e.listenersMu.Lock()
+	defer e.listenersMu.Unlock()
+	return append([]*serverListener(nil), e.listeners...)
+}
+
+// sameAddr report whether addr is the address to listen on the network
+func sameAddr(addr net.Addr, network, address string) bool {
+	switch a := addr.(type) {
+	case *net.UnixAddr:
+		return network == "unix" && a.Name == address
+	case *net.TCPAddr:
+		if !strings.HasPrefix(network, "tcp") {
+			return false
+		}
+		expect, err := net.ResolveTCPAddr(network, address)
+		if err != nil || expect.Port == 0 || expect.Port != a.Port {
+			return false
+		}
+		if len(expect.IP) == 0 || expect.IP.IsUnspecified() {
+			return a.IP.IsUnspecified()
+		}
+		return expect.IP.Equal(a.IP)
+	}
+	return false
+}
+
+// Addrs return the addresses of the listeners opened by Listen
+func (e *EasyGin) Addrs() []net.Addr {
+	listeners := e.listenerList()
+	addrs := make([]net.Addr, 0, len(listeners))
+	for _, l := range listeners {
+		addrs = append(addrs, l.Addr())
+	}
+	return addrs
+}
+
+// Serve serve on l and the listeners opened by Listen, it shuts down gracefully on the signals as ListenAndServe.
+// it returns http.ErrServerClosed after the servers are drained, or the error of any listener which fails,
+// the others are shut down then. see RunContext for the errors of the shutdown
+func (e *EasyGin) Serve(l net.Listener) error {
+	e.addListener(l, l, nil)
+	return e.serve()
+}
+
+// Run serve on addr as gin.Engine.Run, the default address is $PORT or :8080,
+// the requests are served by EasyGin with the graceful shutdown, see ListenAndServe
+func (e *EasyGin) Run(addr ...string) error {
+	switch len(addr) {
+	case 0:
+		if port := os.Getenv("PORT"); port != "" {
+			return e.ListenAndServe(":" + port)
+		}
+		return e.ListenAndServe(":8080")
+	case 1:
+		return e.ListenAndServe(addr[0])
+	default:
+		panic("too many parameters")
+	}
+}
+
+// RunContext serve on the listeners opened by Listen, or on the Addr of EasyGin.Server if there is none, until ctx is done,
+// the signal handler is done or Shutdown is called. it blocks until the servers are drained and the shutdown hooks
+// are called, it returns nil on a clean shutdown, otherwise the errors of the listeners, the draining and the hooks
+func (e *EasyGin) RunContext(ctx context.Context) error {
+	if len(e.listenerList()) == 0 {
+		if e.Server == nil || e.Server.Addr == "" {
+			return errors.New("no listener to serve, call Listen first")
+		}
+		if err := e.Listen(ListenConfig{Addr: e.Server.Addr}); err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			e.startShutdown()
+		case <-e.drained:
+		}
+	}()
+	if err := e.serve(); !errors.Is(err, http.ErrServerClosed) {
+		return errors.Join(err, e.shutdownErr)
+	}
+	return e.shutdownErr
+}
+
+// serve serve all the listeners until one of them returns
+func (e *EasyGin) serve() error {
+	e.Prepare()
+	// the servers are set before the shutdown starts in setupSignal, which reads them in shutdownServers
+	e.listenersMu.Lock()
+	if e.Server == nil {
+		e.Server = &http.Server{Handler: e}
+	}
+	listeners := append([]*serverListener(nil), e.listeners...)
+	for _, l := range listeners {
+		if l.server == nil {
+			l.server = e.Server
+		}
+	}
+	e.listenersMu.Unlock()
+
+	drained := e.setupSignal()
+	errCh := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func(l *serverListener) {
+			errCh <- l.server.Serve(l.Listener)
+		}(l)
+	}
+	err := <-errCh
+	if !errors.Is(err, http.ErrServerClosed) {
+		// a listener fails, shut down the others
+		e.Logger().Log(context.Background(), slog.LevelError, "serve failed", "error", err)
+		e.startShutdown()
+	}
+	<-drained
+	return err
+}
+
+// servers return the distinct servers of the listeners
+func (e *EasyGin) servers() []*http.Server {
+	e.listenersMu.Lock()
+	defer e.listenersMu.Unlock()
+	var servers []*http.Server
+	seen := make(map[*http.Server]bool)
+	for _, l := range e.listeners {
+		if l.server != nil && !seen[l.server] {
+			seen[l.server] = true
+			servers = append(servers, l.server)
+		}
+	}
+	return servers
+}
+
+// shutdownServers shut down the servers of all the listeners gracefully in parallel
+func (e *EasyGin) shutdownServers(ctx context.Context) error {
+	servers := e.servers()
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func(i int, server *http.Server) {
+			defer wg.Done()
+			errs[i] = server.Shutdown(ctx)
+		}(i, server)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,217 @@
+package easygin
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestListeners(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := newBlocker()
+	server := New()
+	server.SetSignalHandler(func() context.Context { return ctx })
+	server.GET("/slow", func() *Response {
+		b.wait()
+		return OkData("slow")
+	})
+
+	socket := filepath.Join(t.TempDir(), "easygin.sock")
+	if err := server.Listen(ListenConfig{Network: "unix", Addr: socket, Mode: 0o600}); err != nil {
+		t.Fatal(err)
+	}
+	admin := http.NewServeMux()
+	admin.HandleFunc("/admin", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("admin"))
+	})
+	if err := server.Listen(ListenConfig{Addr: "127.0.0.1:0", Handler: admin}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(socket); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("unexpected unix socket %v %v", info, err)
+	}
+	l, serveErr := startServer(t, server)
+
+	unixClient := &http.Client{Transport: &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return net.Dial("unix", socket)
+	}}}
+	slow := make(chan string, 1)
+	go func() {
+		_, body := httpGet(unixClient, "http://unix/slow")
+		slow <- body
+	}()
+
+	tests := []struct {
+		name string
+		addr string
+		path string
+		body string
+	}{
+		{"handler of the listener", server.Addrs()[1].String(), "/admin", "admin"},
+		{"served listener", l.Addr().String(), "/none", "404 page not found"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, body := httpGet(http.DefaultClient, "http://"+test.addr+test.path); body != test.body {
+				t.Errorf("expect %s, got %s", test.body, body)
+			}
+		})
+	}
+
+	// the in-flight request of the unix socket is drained by the shutdown
+	b.drain(cancel)
+	if body := <-slow; body != `{"data":"slow","code":0,"message":"success"}` {
+		t.Errorf("unexpected slow response %s", body)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("expect ErrServerClosed, got %v", err)
+	}
+	if _, err := net.Dial("tcp", server.Addrs()[1].String()); err == nil {
+		t.Error("expect the admin listener to be closed")
+	}
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "easygin.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the socket of a live server is kept
+	if err = New().Listen(ListenConfig{Network: "unix", Addr: socket}); err == nil {
+		t.Error("expect the error of the socket in use")
+	}
+	if conn, err := net.Dial("unix", socket); err != nil {
+		t.Errorf("expect the socket to be kept, got %v", err)
+	} else {
+		_ = conn.Close()
+	}
+
+	// the stale socket is removed
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = l.Close()
+	server := New()
+	if err = server.Listen(ListenConfig{Network: "unix", Addr: socket}); err != nil {
+		t.Fatalf("expect the stale socket to be removed, got %v", err)
+	}
+	for _, l := range server.listenerList() {
+		_ = l.Close()
+	}
+}
+
+func TestRun(t *testing.T) {
+	newServer := func(hookErr error) (*EasyGin, *atomic.Bool) {
+		server := New()
+		server.SetSignalHandler(context.Background)
+		server.GET("/ping", func() *Response {
+			return Ok()
+		})
+		var hooked atomic.Bool
+		server.OnShutdown("close", AfterDrain, func(ctx context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			hooked.Store(true)
+			return hookErr
+		})
+		if err := server.Listen(ListenConfig{Addr: "127.0.0.1:0"}); err != nil {
+			t.Fatal(err)
+		}
+		return server, &hooked
+	}
+
+	t.Run("context done", func(t *testing.T) {
+		// RunContext returns nil after the hooks are called when ctx is done
+		server, hooked := newServer(nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		runErr := make(chan error, 1)
+		go func() {
+			runErr <- server.RunContext(ctx)
+		}()
+		waitServing(t, server.Addrs()[0].String())
+		cancel()
+		if err := <-runErr; err != nil || !hooked.Load() {
+			t.Errorf("expect a clean shutdown after the hooks, got %v %v", err, hooked.Load())
+		}
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		// Shutdown returns the errors of the hooks, which are also returned by RunContext
+		errClose := errors.New("close failed")
+		server, hooked := newServer(errClose)
+		runErr := make(chan error, 1)
+		go func() {
+			runErr <- server.RunContext(context.Background())
+		}()
+		waitServing(t, server.Addrs()[0].String())
+		if err := server.Shutdown(context.Background()); !errors.Is(err, errClose) || !hooked.Load() {
+			t.Errorf("expect the error of the hook after it is called, got %v %v", err, hooked.Load())
+		}
+		if err := <-runErr; !errors.Is(err, errClose) {
+			t.Errorf("expect the error of the hook, got %v", err)
+		}
+	})
+
+	t.Run("shutdown timeout", func(t *testing.T) {
+		// Shutdown returns the error of ctx if it is done before the shutdown finishes
+		server, _ := newServer(nil)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		if err := server.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expect the error of ctx, got %v", err)
+		}
+	})
+
+	t.Run("shutdown before serving", func(t *testing.T) {
+		// the server shuts down once it is served
+		server, hooked := newServer(nil)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+		if err := server.RunContext(context.Background()); err != nil || !hooked.Load() {
+			t.Errorf("expect a clean shutdown after the hooks, got %v %v", err, hooked.Load())
+		}
+	})
+
+	t.Run("run", func(t *testing.T) {
+		// Run serves through EasyGin, so Shutdown drains it and calls the hooks
+		server := New()
+		server.SetSignalHandler(context.Background)
+		server.GET("/ping", func() *Response {
+			return Ok()
+		})
+		var hooked atomic.Bool
+		server.OnShutdown("close", AfterDrain, func(ctx context.Context) error {
+			hooked.Store(true)
+			return nil
+		})
+		runErr := make(chan error, 1)
+		go func() {
+			runErr <- server.Run("127.0.0.1:0")
+		}()
+		for i := 0; i < 100 && len(server.Addrs()) == 0; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if len(server.Addrs()) == 0 {
+			t.Fatal("expect Run to listen")
+		}
+		waitServing(t, server.Addrs()[0].String())
+		if err := server.Shutdown(context.Background()); err != nil || !hooked.Load() {
+			t.Errorf("expect a clean shutdown after the hooks, got %v %v", err, hooked.Load())
+		}
+		if err := <-runErr; !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("expect http.ErrServerClosed, got %v", err)
+		}
+	})
+
+	t.Run("no listeners", func(t *testing.T) {
+		if err := New().RunContext(context.Background()); err == nil {
+			t.Error("expect the error without listeners")
+		}
+	})
+}
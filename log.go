@@ -0,0 +1,137 @@
+package easygin
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Logger is used by easygin to output shutdown, profiling, dump and access messages
+// *slog.Logger implements this interface, so it can be passed to EasyGin.SetLogger directly,
+// the request id carried by ctx is added to every record of a *slog.Logger automatically,
+// other implementations can get it by RequestIDFromContext
+type Logger interface {
+	Log(ctx context.Context, level slog.Level, msg string, args ...interface{})
+}
+
+var (
+	stderrLogger = newTextLogger(os.Stderr)
+	// defaultLog is replaced by SetLogOutput and SetDefaultLogger while the other goroutines are logging
+	defaultLog atomic.Pointer[Logger]
+)
+
+// defaultLogger return the logger used by package level functions and the EasyGin without SetLogger
+func defaultLogger() Logger {
+	if l := defaultLog.Load(); l != nil {
+		return *l
+	}
+	return stderrLogger
+}
+
+func newTextLogger(out io.Writer) Logger {
+	return withRequestID(slog.New(slog.NewTextHandler(out, nil)).With("logger", "EasyGin"))
+}
+
+// SetLogOutput set the output of the default logger,
+// it does not affect the EasyGin instance which has called SetLogger
+func SetLogOutput(out io.Writer) {
+	l := newTextLogger(out)
+	defaultLog.Store(&l)
+}
+
+// SetDefaultLogger replace the default logger used by package level functions
+// such as StartProfile and SetupSignalHandler
+func SetDefaultLogger(l Logger) {
+	if l == nil {
+		l = stderrLogger
+	} else {
+		l = withRequestID(l)
+	}
+	defaultLog.Store(&l)
+}
+
+// SetLogger set the logger of the EasyGin instance
+func (e *EasyGin) SetLogger(l Logger) {
+	if l != nil {
+		l = withRequestID(l)
+	}
+	e.logger = l
+}
+
+// Logger return the logger of the EasyGin instance, default logger will be returned if not set
+func (e *EasyGin) Logger() Logger {
+	if e.logger == nil {
+		return defaultLogger()
+	}
+	return e.logger
+}
+
+const (
+	// respCodeKey is the key of the business code stored in gin.Context
+	respCodeKey = "easygin/code"
+
+	headerRequestID = "X-Request-ID"
+)
+
+// AccessLog return a middleware which outputs an access log for every request
+// with the following fields: method, route, path, status, code, latency, client_ip, request_id
+// the level is Error for 5xx, Warn for 4xx and Info for others
+func (e *EasyGin) AccessLog() gin.HandlerFunc {
+	return accessLog(e.Logger)
+}
+
+// AccessLog return an access log middleware which outputs to l
+func AccessLog(l Logger) gin.HandlerFunc {
+	l = withRequestID(l)
+	return accessLog(func() Logger { return l })
+}
+
+func accessLog(logger func() Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+		latency := time.Since(start)
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = ctx.Request.URL.Path
+		}
+		status := ctx.Writer.Status()
+
+		args := make([]interface{}, 0, 16)
+		args = append(args,
+			"method", ctx.Request.Method,
+			"route", route,
+			"path", ctx.Request.URL.Path,
+			"status", status,
+		)
+		if code, ok := ctx.Get(respCodeKey); ok {
+			args = append(args, "code", code)
+		}
+		args = append(args, "latency", latency, "client_ip", ctx.ClientIP())
+		l := logger()
+		// the request id carried by the context is added by *slog.Logger itself
+		if id := RequestIDFromContext(ctx.Request.Context()); id == "" {
+			if id = ctx.GetHeader(headerRequestID); id != "" {
+				args = append(args, "request_id", id)
+			}
+		} else if _, ok := l.(*slog.Logger); !ok {
+			args = append(args, "request_id", id)
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		l.Log(ctx.Request.Context(), level, "access", args...)
+	}
+}
@@ -0,0 +1,56 @@
+package easygin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccessLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	server := New()
+	server.SetLogger(slog.New(slog.NewJSONHandler(buf, nil)))
+	server.Use(server.AccessLog())
+	server.GET("/user/:id", func(ctx *gin.Context) *Response {
+		return Fail(NewError(3, "not found"))
+	})
+
+	serveRequest(server, http.MethodGet, "/user/1", nil, "X-Request-ID", "abc")
+
+	record := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["route"] != "/user/:id" || record["status"] != float64(200) ||
+		record["code"] != float64(3) || record["request_id"] != "abc" {
+		t.Errorf("unexpected access log: %s", buf.String())
+	}
+}
+
+func TestSetDefaultLogger(t *testing.T) {
+	defer SetDefaultLogger(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			SetLogOutput(&bytes.Buffer{})
+			New().Logger().Log(context.Background(), slog.LevelDebug, "replaced concurrently")
+		}()
+	}
+	wg.Wait()
+
+	buf := &bytes.Buffer{}
+	SetDefaultLogger(slog.New(slog.NewTextHandler(buf, nil)))
+	New().Logger().Log(ContextWithRequestID(context.Background(), "req-1"), slog.LevelInfo, "default")
+	if !strings.Contains(buf.String(), "msg=default request_id=req-1") {
+		t.Errorf("unexpected log %s", buf.String())
+	}
+}
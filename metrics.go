@@ -0,0 +1,265 @@
+package easygin
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMetricsBuckets is the default buckets of the request latency histogram, in seconds
+var DefaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+const contentTypeMetrics = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsConfig is used to configure the metrics subsystem
+type MetricsConfig struct {
+	// Path is the path of the metrics endpoint, default is /metrics
+	Path string
+	// Namespace is the prefix of the metric names, default is easygin
+	Namespace string
+	// Buckets is the buckets of the request latency histogram, default is DefaultMetricsBuckets
+	Buckets []float64
+}
+
+// EnableMetrics enable the metrics of the routes registered through EasyGin and RouterGroup,
+// and expose them in the Prometheus text format at the configured path.
+// the following metrics are collected:
+//
+//	<namespace>_requests_total{route,method,status,code}
+//	<namespace>_request_duration_seconds{route,method,status,code}
+//	<namespace>_bind_failures_total{route,method}
+//	<namespace>_requests_in_flight
+//	<namespace>_shutdown_duration_seconds
+func (e *EasyGin) EnableMetrics(configs ...MetricsConfig) {
+	var cfg MetricsConfig
+	if len(configs) > 0 {
+		cfg = configs[0]
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/metrics"
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = "easygin"
+	}
+	if len(cfg.Buckets) == 0 {
+		cfg.Buckets = DefaultMetricsBuckets
+	}
+
+	e.metrics = newMetrics(cfg)
+	e.Engine.GET(cfg.Path, e.metrics.serve)
+}
+
+type metrics struct {
+	requests         *counterVec
+	durations        *histogramVec
+	bindFailures     *counterVec
+	inFlight         int64
+	shutdownDuration uint64
+
+	namespace string
+}
+
+func newMetrics(cfg MetricsConfig) *metrics {
+	buckets := append([]float64(nil), cfg.Buckets...)
+	sort.Float64s(buckets)
+	return &metrics{
+		requests:     &counterVec{labels: []string{"route", "method", "status", "code"}},
+		durations:    &histogramVec{labels: []string{"route", "method", "status", "code"}, buckets: buckets},
+		bindFailures: &counterVec{labels: []string{"route", "method"}},
+		namespace:    cfg.Namespace,
+	}
+}
+
+func (rt *route) metrics() *metrics {
+	if rt == nil || rt.engine == nil {
+		return nil
+	}
+	return rt.engine.metrics
+}
+
+// measureRoute collects the request metrics of the route
+func (rt *route) measureRoute(ctx *gin.Context) {
+	m := rt.metrics()
+	if m == nil {
+		return
+	}
+
+	atomic.AddInt64(&m.inFlight, 1)
+	start := time.Now()
+	defer func() {
+		atomic.AddInt64(&m.inFlight, -1)
+
+		code := ""
+		if c, ok := ctx.Get(respCodeKey); ok {
+			code = strconv.Itoa(c.(int))
+		}
+		status := strconv.Itoa(ctx.Writer.Status())
+		method := rt.requestMethod(ctx)
+		m.requests.inc(rt.path, method, status, code)
+		m.durations.observe(time.Since(start).Seconds(), rt.path, method, status, code)
+	}()
+
+	ctx.Next()
+}
+
+func (rt *route) bindFailed() {
+	if m := rt.metrics(); m != nil {
+		m.bindFailures.inc(rt.path, rt.method)
+	}
+}
+
+func (m *metrics) observeShutdown(d time.Duration) {
+	atomic.StoreUint64(&m.shutdownDuration, math.Float64bits(d.Seconds()))
+}
+
+func (m *metrics) serve(ctx *gin.Context) {
+	ctx.Status(http.StatusOK)
+	ctx.Header("Content-Type", contentTypeMetrics)
+
+	w := bufio.NewWriter(ctx.Writer)
+	m.requests.write(w, m.namespace+"_requests_total", "Total number of requests.")
+	m.durations.write(w, m.namespace+"_request_duration_seconds", "Latency of requests in seconds.")
+	m.bindFailures.write(w, m.namespace+"_bind_failures_total", "Total number of parameter binding failures.")
+	writeGauge(w, m.namespace+"_requests_in_flight", "Number of requests being served.",
+		float64(atomic.LoadInt64(&m.inFlight)))
+	writeGauge(w, m.namespace+"_shutdown_duration_seconds", "Duration of the last graceful shutdown in seconds.",
+		math.Float64frombits(atomic.LoadUint64(&m.shutdownDuration)))
+	_ = w.Flush()
+}
+
+type counterVec struct {
+	labels []string
+	series sync.Map // label values joined by labelSep --> *uint64
+}
+
+const labelSep = "\xff"
+
+func (c *counterVec) inc(values ...string) {
+	key := strings.Join(values, labelSep)
+	v, ok := c.series.Load(key)
+	if !ok {
+		v, _ = c.series.LoadOrStore(key, new(uint64))
+	}
+	atomic.AddUint64(v.(*uint64), 1)
+}
+
+func (c *counterVec) write(w *bufio.Writer, name, help string) {
+	writeHeader(w, name, help, "counter")
+	for _, key := range sortedKeys(&c.series) {
+		v, _ := c.series.Load(key)
+		fmt.Fprintf(w, "%s%s %d\n", name, formatLabels(c.labels, key, "", ""), atomic.LoadUint64(v.(*uint64)))
+	}
+}
+
+type histogramVec struct {
+	labels  []string
+	buckets []float64
+	series  sync.Map // label values joined by labelSep --> *histogram
+}
+
+type histogram struct {
+	mu      sync.Mutex
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+func (h *histogramVec) observe(v float64, values ...string) {
+	key := strings.Join(values, labelSep)
+	s, ok := h.series.Load(key)
+	if !ok {
+		s, _ = h.series.LoadOrStore(key, &histogram{buckets: make([]uint64, len(h.buckets))})
+	}
+
+	hist := s.(*histogram)
+	i := sort.SearchFloat64s(h.buckets, v)
+	hist.mu.Lock()
+	if i < len(hist.buckets) {
+		hist.buckets[i]++
+	}
+	hist.count++
+	hist.sum += v
+	hist.mu.Unlock()
+}
+
+func (h *histogramVec) write(w *bufio.Writer, name, help string) {
+	writeHeader(w, name, help, "histogram")
+	for _, key := range sortedKeys(&h.series) {
+		s, _ := h.series.Load(key)
+		hist := s.(*histogram)
+
+		hist.mu.Lock()
+		buckets := append([]uint64(nil), hist.buckets...)
+		count, sum := hist.count, hist.sum
+		hist.mu.Unlock()
+
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += buckets[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name,
+				formatLabels(h.labels, key, "le", strconv.FormatFloat(upper, 'g', -1, 64)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, formatLabels(h.labels, key, "le", "+Inf"), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, formatLabels(h.labels, key, "", ""), strconv.FormatFloat(sum, 'g', -1, 64))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, formatLabels(h.labels, key, "", ""), count)
+	}
+}
+
+func writeGauge(w *bufio.Writer, name, help string, v float64) {
+	writeHeader(w, name, help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", name, strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+func writeHeader(w *bufio.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func sortedKeys(m *sync.Map) []string {
+	var keys []string
+	m.Range(func(key, _ interface{}) bool {
+		keys = append(keys, key.(string))
+		return true
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// formatLabels format the label pairs, extraName and extraValue are appended if extraName is not empty
+func formatLabels(names []string, key, extraName, extraValue string) string {
+	values := strings.Split(key, labelSep)
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(name)
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabelValue(values[i]))
+		sb.WriteByte('"')
+	}
+	if extraName != "" {
+		sb.WriteByte(',')
+		sb.WriteString(extraName)
+		sb.WriteString(`="`)
+		sb.WriteString(extraValue)
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(v)
+}
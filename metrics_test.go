@@ -0,0 +1,33 @@
+package easygin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetrics(t *testing.T) {
+	server := New()
+	server.EnableMetrics(MetricsConfig{Path: "/internal/metrics"})
+	server.GET("/user/:id", func(ctx *gin.Context, id int) *Response {
+		return Fail(NewError(7, "fail"))
+	})
+
+	serveRequest(server, http.MethodGet, "/user/1?id=1", nil)
+	serveRequest(server, http.MethodGet, "/user/1", nil)
+
+	body := serveRequest(server, http.MethodGet, "/internal/metrics", nil).Body.String()
+	for _, line := range []string{
+		`easygin_requests_total{route="/user/:id",method="GET",status="200",code="7"} 1`,
+		`easygin_request_duration_seconds_count{route="/user/:id",method="GET",status="200",code="7"} 1`,
+		`easygin_bind_failures_total{route="/user/:id",method="GET"} 1`,
+		`easygin_requests_in_flight 0`,
+		"# TYPE easygin_request_duration_seconds histogram",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("metrics do not contain %q:\n%s", line, body)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package easygin
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware wraps the typed handlers, next binds the parameters and calls the handler, or calls the next middleware.
+// it can inspect or replace the Response returned by next before it is rendered, or return without calling next,
+// next returns nil if the binding fails and the request has been aborted
+type Middleware func(ctx *gin.Context, next func() *Response) *Response
+
+// Use attach middlewares to the group, a middleware can be a typed Middleware,
+// a gin.HandlerFunc or a typed handler, typed middlewares only wrap the routes registered after Use
+func (r *RouterGroup) Use(middlewares ...Handler) {
+	ginMiddlewares, typed := splitMiddlewares(middlewares)
+	r.RouterGroup.Use(ginMiddlewares...)
+	r.middlewares = append(r.middlewares, typed...)
+}
+
+// Group create a sub group, it inherits the middlewares, interceptors, timeout and guards of r, handlers are used as Use
+func (r *RouterGroup) Group(relativePath string, handlers ...Handler) *RouterGroup {
+	ginMiddlewares, typed := splitMiddlewares(handlers)
+	middlewares := make([]Middleware, 0, len(r.middlewares)+len(typed))
+	middlewares = append(append(middlewares, r.middlewares...), typed...)
+	return &RouterGroup{
+		RouterGroup:  r.RouterGroup.Group(relativePath, ginMiddlewares...),
+		engine:       r.engine,
+		middlewares:  middlewares,
+		interceptors: append([]Interceptor(nil), r.interceptors...),
+		version:      r.version,
+		timeout:      r.timeout,
+		guards:       append([]guard(nil), r.guards...),
+	}
+}
+
+// splitMiddlewares split the typed middlewares from the middlewares of gin,
+// typed handlers are converted to gin.HandlerFunc
+func splitMiddlewares(handlers []Handler) ([]gin.HandlerFunc, []Middleware) {
+	var (
+		ginMiddlewares []gin.HandlerFunc
+		typed          []Middleware
+	)
+	for _, h := range handlers {
+		switch m := h.(type) {
+		case Middleware:
+			typed = append(typed, m)
+		case func(*gin.Context, func() *Response) *Response:
+			typed = append(typed, m)
+		case gin.HandlerFunc:
+			ginMiddlewares = append(ginMiddlewares, m)
+		case func(*gin.Context):
+			ginMiddlewares = append(ginMiddlewares, m)
+		default:
+			ginMiddlewares = append(ginMiddlewares, ginHandlers(m)...)
+		}
+	}
+	return ginMiddlewares, typed
+}
+
+// runMiddlewares call the middlewares in order, next of the last middleware calls handler
+func runMiddlewares(ctx *gin.Context, middlewares []Middleware, handler func() *Response) *Response {
+	if len(middlewares) == 0 {
+		return handler()
+	}
+	return middlewares[0](ctx, func() *Response {
+		return runMiddlewares(ctx, middlewares[1:], handler)
+	})
+}
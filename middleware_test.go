@@ -0,0 +1,70 @@
+package easygin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(ctx *gin.Context, next func() *Response) *Response {
+			calls = append(calls, name)
+			return next()
+		}
+	}
+
+	server := New()
+	server.Use(trace("engine"), gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Header("X-Gin", "1")
+	}))
+	api := server.Group("/api", trace("api"))
+	v1 := api.Group("/v1")
+	v1.Use(func(ctx *gin.Context, next func() *Response) *Response {
+		if ctx.GetHeader("X-Token") == "" {
+			return FailStatus(http.StatusUnauthorized, NewError(401, "unauthorized"))
+		}
+		resp := next()
+		if resp != nil && resp.R.Data == nil {
+			return OkData("replaced")
+		}
+		return resp
+	})
+	v1.GET("/user", func(ctx *gin.Context, id int) *Response {
+		return OkData(id)
+	})
+	v1.GET("/empty", func() *Response {
+		return Ok()
+	})
+	api.GET("/ping", func() *Response {
+		return Ok()
+	})
+
+	tests := []struct {
+		name, path, token string
+		status            int
+		body              string
+		calls             string
+	}{
+		{"handler", "/api/v1/user?id=3", "t", 200, `{"data":3,"code":0,"message":"success"}`, "engine,api"},
+		{"replaced", "/api/v1/empty", "t", 200, `{"data":"replaced","code":0,"message":"success"}`, "engine,api"},
+		{"short circuit", "/api/v1/user?id=3", "", 401, `{"data":null,"code":401,"message":"unauthorized"}`, "engine,api"},
+		{"parent group", "/api/ping", "", 200, `{"data":null,"code":0,"message":"success"}`, "engine,api"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls = nil
+			var header []string
+			if test.token != "" {
+				header = []string{"X-Token", test.token}
+			}
+			w := serveRequest(server, http.MethodGet, test.path, nil, header...)
+			if w.Code != test.status || w.Body.String() != test.body || strings.Join(calls, ",") != test.calls || w.Header().Get("X-Gin") != "1" {
+				t.Errorf("expect %d %s %s, got %d %s %v", test.status, test.body, test.calls, w.Code, w.Body.String(), calls)
+			}
+		})
+	}
+}
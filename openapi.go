@@ -0,0 +1,551 @@
+package easygin
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+const openAPIVersion = "3.1.0"
+
+// OpenAPIConfig is used to configure the generated OpenAPI document
+type OpenAPIConfig struct {
+	// Path is the prefix of the OpenAPI endpoints, default is /openapi,
+	// the document is served at <Path>.json and <Path>.yaml, and the Swagger UI page at <Path>/ui
+	Path        string
+	Title       string
+	Version     string
+	Description string
+	Servers     []string
+	// SwaggerUIAssets is the base URL of the swagger-ui-dist assets loaded by the Swagger UI page,
+	// the assets are not bundled, default is DefaultSwaggerUIAssets on the unpkg CDN.
+	// set it to a self-hosted copy for offline or air-gapped deployments, etc.: served by StaticFS
+	SwaggerUIAssets string
+	// SwaggerUIIntegrity is the subresource integrity of the assets, the browser refuses the assets
+	// which do not match. set it when the assets are loaded from a CDN
+	SwaggerUIIntegrity SwaggerUIIntegrity
+}
+
+// SwaggerUIIntegrity is the subresource integrity hashes of swagger-ui.css and swagger-ui-bundle.js,
+// etc.: "sha384-" + base64 of the sha384 of the file, computed by
+// `openssl dgst -sha384 -binary swagger-ui.css | openssl base64 -A`
+type SwaggerUIIntegrity struct {
+	CSS string
+	JS  string
+}
+
+// DefaultSwaggerUIAssets is the default base URL of the swagger-ui-dist assets, the version is pinned
+// so that the assets do not change under SwaggerUIIntegrity
+const DefaultSwaggerUIAssets = "https://unpkg.com/swagger-ui-dist@5.17.14"
+
+// OpenAPIDocument is the OpenAPI 3.1 document generated from the registered routes,
+// only the parts used by easygin are defined
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Servers    []OpenAPIServer                         `json:"servers,omitempty"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+type OpenAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+type OpenAPIParameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema   *Schema                    `json:"schema"`
+	Examples map[string]*OpenAPIExample `json:"examples,omitempty"`
+}
+
+type OpenAPIExample struct {
+	Summary string      `json:"summary,omitempty"`
+	Value   interface{} `json:"value"`
+}
+
+// Schema is the JSON schema of a type
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Description          string             `json:"description,omitempty"`
+}
+
+// Errors declare the RespError which may be returned by the handler,
+// they are listed in the route introspection and the OpenAPI document
+func Errors(errs ...RespError) RouteOption {
+	return routeOptionFunc(func(rt *route) {
+		for _, err := range errs {
+			rt.info.Errors = append(rt.info.Errors, ErrorInfo{Code: err.Code(), Message: err.Message()})
+		}
+	})
+}
+
+// ErrorInfo describes a RespError declared by Errors
+type ErrorInfo struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// OpenAPI generate the OpenAPI document of the routes registered through EasyGin and RouterGroup
+func (e *EasyGin) OpenAPI(cfg OpenAPIConfig) *OpenAPIDocument {
+	e.Prepare()
+	if cfg.Title == "" {
+		cfg.Title = "EasyGin API"
+	}
+	if cfg.Version == "" {
+		cfg.Version = "1.0.0"
+	}
+
+	doc := &OpenAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    OpenAPIInfo{Title: cfg.Title, Version: cfg.Version, Description: cfg.Description},
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+	}
+	for _, server := range cfg.Servers {
+		doc.Servers = append(doc.Servers, OpenAPIServer{URL: server})
+	}
+
+	g := newSchemaGenerator()
+	operationIDs := make(map[string]bool)
+	for _, info := range e.RegisteredRoutes() {
+		// CONNECT registered by Any has no operation in OpenAPI
+		if info.Method == http.MethodConnect {
+			continue
+		}
+		p := openAPIPath(info.Path)
+		if doc.Paths[p] == nil {
+			doc.Paths[p] = make(map[string]*OpenAPIOperation)
+		}
+		op := g.operation(&info)
+		if operationIDs[op.OperationID] {
+			op.OperationID = pathOperationID(info.Method, info.Path)
+		}
+		operationIDs[op.OperationID] = true
+		doc.Paths[p][strings.ToLower(info.Method)] = op
+	}
+	doc.Components.Schemas = g.components
+
+	return doc
+}
+
+// ServeOpenAPI serve the OpenAPI document as JSON at <Path>.json and YAML at <Path>.yaml,
+// and a Swagger UI page at <Path>/ui which loads its assets from SwaggerUIAssets. the document is
+// generated at the first request, so routes registered after calling ServeOpenAPI are included
+func (e *EasyGin) ServeOpenAPI(cfg OpenAPIConfig) {
+	if cfg.Path == "" {
+		cfg.Path = "/openapi"
+	}
+	cfg.Path = strings.TrimSuffix(cfg.Path, "/")
+	if cfg.SwaggerUIAssets == "" {
+		cfg.SwaggerUIAssets = DefaultSwaggerUIAssets
+	}
+	cfg.SwaggerUIAssets = strings.TrimSuffix(cfg.SwaggerUIAssets, "/")
+
+	var (
+		once     sync.Once
+		jsonData []byte
+		yamlData []byte
+		err      error
+	)
+	generate := func() {
+		doc := e.OpenAPI(cfg)
+		if jsonData, err = json.MarshalIndent(doc, "", "  "); err != nil {
+			return
+		}
+		yamlData, err = jsonToYAML(jsonData)
+	}
+
+	e.Engine.GET(cfg.Path+".json", func(ctx *gin.Context) {
+		once.Do(generate)
+		if err != nil {
+			ctx.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		ctx.Data(http.StatusOK, ContentTypeJson, jsonData)
+	})
+	e.Engine.GET(cfg.Path+".yaml", func(ctx *gin.Context) {
+		once.Do(generate)
+		if err != nil {
+			ctx.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		ctx.Data(http.StatusOK, "application/yaml", yamlData)
+	})
+	page := fmt.Sprintf(swaggerUIPage, cfg.SwaggerUIAssets, integrityAttr(cfg.SwaggerUIIntegrity.CSS),
+		cfg.SwaggerUIAssets, integrityAttr(cfg.SwaggerUIIntegrity.JS), cfg.Path+".json")
+	e.Engine.GET(cfg.Path+"/ui", func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+	})
+}
+
+// swaggerUIPage loads the Swagger UI assets from the base URL
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>Swagger UI</title>
+  <link rel="stylesheet" href="%s/swagger-ui.css" crossorigin%s />
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="%s/swagger-ui-bundle.js" crossorigin%s></script>
+<script>
+  window.onload = () => {
+    window.ui = SwaggerUIBundle({ url: "%s", dom_id: "#swagger-ui" });
+  };
+</script>
+</body>
+</html>
+`
+
+// integrityAttr return the integrity attribute of the hash, empty if the hash is not set
+func integrityAttr(hash string) string {
+	if hash == "" {
+		return ""
+	}
+	return ` integrity="` + html.EscapeString(hash) + `"`
+}
+
+// jsonToYAML convert the json document to yaml, the order of the keys is kept
+func jsonToYAML(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// resetYAMLStyle output the nodes in block style, strings are quoted by the encoder only if necessary
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		resetYAMLStyle(n)
+	}
+}
+
+// openAPIPath convert the path template of gin to OpenAPI, etc.: /user/:id --> /user/{id}
+func openAPIPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		if s != "" && (s[0] == ':' || s[0] == '*') {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pathOperationID generate operation id from method and path, etc.: GET /user/:id --> getUserId
+func pathOperationID(method, p string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	upper := true
+	for _, r := range p {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// handlerOperationID return the name of the handler if it is a named function
+func handlerOperationID(handler string) string {
+	name := handler[strings.LastIndex(handler, "/")+1:]
+	name = name[strings.Index(name, ".")+1:]
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return ""
+		}
+	}
+	return name
+}
+
+type schemaGenerator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *schemaGenerator) operation(info *RouteInfo) *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		OperationID: handlerOperationID(info.Handler),
+		Summary:     info.Summary,
+		Responses:   make(map[string]*OpenAPIResponse),
+	}
+	if op.OperationID == "" {
+		op.OperationID = pathOperationID(info.Method, info.Path)
+	}
+
+	pathParams := make(map[string]bool)
+	for _, segment := range strings.Split(info.Path, "/") {
+		if segment != "" && (segment[0] == ':' || segment[0] == '*') {
+			pathParams[segment[1:]] = true
+		}
+	}
+	hasBody := info.Method == http.MethodPost || info.Method == http.MethodPut || info.Method == http.MethodPatch
+	queryIndex := 0
+	for _, in := range info.params {
+		if in == ginCtxType || info.authenticated && isPrincipalType(in) {
+			continue
+		}
+		if in.Kind() == reflect.Pointer {
+			in = in.Elem()
+		}
+		if in.Kind() != reflect.Struct {
+			// the names of the positional query values are unknown
+			op.Parameters = append(op.Parameters, &OpenAPIParameter{
+				Name:     "arg" + strconv.Itoa(queryIndex),
+				In:       "query",
+				Required: true,
+				Schema:   g.schemaOf(in),
+			})
+			queryIndex++
+			continue
+		}
+
+		for _, f := range structFields(in) {
+			switch {
+			case tagName(f.Tag.Get("uri")) != "":
+				// uri fields are only bound if the path has the param
+				if name := tagName(f.Tag.Get("uri")); pathParams[name] {
+					op.Parameters = append(op.Parameters, &OpenAPIParameter{
+						Name: name, In: "path", Required: true, Schema: g.schemaOf(f.Type)})
+				}
+			case tagName(f.Tag.Get("header")) != "":
+				op.Parameters = append(op.Parameters, &OpenAPIParameter{
+					Name: tagName(f.Tag.Get("header")), In: "header",
+					Required: hasTagOption(f.Tag.Get("binding"), "required"), Schema: g.schemaOf(f.Type)})
+			case !hasBody && f.Tag.Get("form") != "-":
+				name := tagName(f.Tag.Get("form"))
+				if name == "" {
+					name = f.Name
+				}
+				op.Parameters = append(op.Parameters, &OpenAPIParameter{
+					Name: name, In: "query",
+					Required: hasTagOption(f.Tag.Get("binding"), "required"), Schema: g.schemaOf(f.Type)})
+			}
+		}
+		if hasBody {
+			op.RequestBody = &OpenAPIRequestBody{
+				Required: true,
+				Content:  map[string]*OpenAPIMediaType{ContentTypeJson: {Schema: g.schemaOf(in)}},
+			}
+		}
+	}
+
+	data := &Schema{}
+	if info.response != nil {
+		data = g.schemaOf(info.response)
+	}
+	codes := []interface{}{SuccessCode}
+	examples := map[string]*OpenAPIExample{
+		"success": {Value: map[string]interface{}{"data": nil, "code": SuccessCode, "message": RespSuccess.Message()}},
+	}
+	for _, e := range info.Errors {
+		codes = append(codes, e.Code)
+		examples["code_"+strconv.Itoa(e.Code)] = &OpenAPIExample{
+			Summary: e.Message,
+			Value:   map[string]interface{}{"data": nil, "code": e.Code, "message": e.Message},
+		}
+	}
+	envelope := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"data":    data,
+			"code":    {Type: "integer", Enum: codes},
+			"message": {Type: "string"},
+		},
+		Required: []string{"data", "code", "message"},
+	}
+	op.Responses["200"] = &OpenAPIResponse{
+		Description: "the response envelope, code is 0 on success",
+		Content:     map[string]*OpenAPIMediaType{ContentTypeJson: {Schema: envelope, Examples: examples}},
+	}
+	if len(op.Parameters) > 0 || op.RequestBody != nil {
+		op.Responses["400"] = &OpenAPIResponse{Description: "failed to bind the request parameters"}
+	}
+
+	return op
+}
+
+// structFields return the exported fields of t, fields of embedded structs are flattened
+func structFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, structFields(ft)...)
+				continue
+			}
+		}
+		if f.IsExported() {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		min := 0.0
+		return &Schema{Type: "integer", Minimum: &min}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.componentName(t)}
+	}
+
+	// interface and the other kinds accept any value
+	return &Schema{}
+}
+
+// componentName return the name of the component schema of t, the schema is generated when first used
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := componentKey(t.Name())
+	if _, ok := g.components[name]; ok {
+		pkg := t.PkgPath()
+		name = strings.ReplaceAll(pkg[strings.LastIndex(pkg, "/")+1:], ".", "_") + "." + name
+		for i := 2; g.components[name] != nil; i++ {
+			name = componentKey(t.Name()) + strconv.Itoa(i)
+		}
+	}
+	g.names[t] = name
+	// placeholder to support recursive types
+	g.components[name] = &Schema{}
+	*g.components[name] = *g.structSchema(t)
+	return name
+}
+
+var (
+	typeArgPackage       = regexp.MustCompile(`[^\[\],/]*/`)
+	invalidComponentChar = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// componentKey convert the type name to a valid key of the components, which only contains
+// letters, digits, ".", "-" and "_", etc.: Page[github.com/a/model.User] --> Page_model.User
+func componentKey(name string) string {
+	name = typeArgPackage.ReplaceAllString(name, "")
+	return strings.Trim(invalidComponentChar.ReplaceAllString(name, "_"), "_")
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, f := range structFields(t) {
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := tagName(tag)
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = g.schemaOf(f.Type)
+		if hasTagOption(f.Tag.Get("binding"), "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+package easygin
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+type CreateUserReq struct {
+	Token string `header:"X-Token" json:"-"`
+	User
+	Tags []string `json:"tags"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	server := New()
+	server.ServeOpenAPI(OpenAPIConfig{Title: "test"})
+	server.GET("/user/:id", Returns[User](), Errors(UsernameInvalidErr), func(ctx *gin.Context, req *GetUserReq) *Response {
+		return OkData(User{})
+	})
+	server.POST("/user", Summary("create user"), func(ctx *gin.Context, req *CreateUserReq) *Response {
+		return Ok()
+	})
+
+	w := serveRequest(server, http.MethodGet, "/openapi.json", nil)
+	doc := &OpenAPIDocument{}
+	if err := json.Unmarshal(w.Body.Bytes(), doc); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("get", func(t *testing.T) {
+		get := doc.Paths["/user/{id}"]["get"]
+		if get == nil || len(get.Parameters) != 3 {
+			t.Fatalf("unexpected get operation: %s", w.Body.String())
+		}
+		if p := get.Parameters[0]; p.Name != "id" || p.In != "path" || !p.Required || p.Schema.Type != "integer" {
+			t.Errorf("unexpected path parameter: %+v", p)
+		}
+		if p := get.Parameters[1]; p.Name != "X-Token" || p.In != "header" {
+			t.Errorf("unexpected header parameter: %+v", p)
+		}
+		if p := get.Parameters[2]; p.Name != "email" || p.In != "query" {
+			t.Errorf("unexpected query parameter: %+v", p)
+		}
+		envelope := get.Responses["200"].Content[ContentTypeJson]
+		if envelope.Schema.Properties["data"].Ref != "#/components/schemas/User" ||
+			len(envelope.Schema.Properties["code"].Enum) != 2 || envelope.Examples["code_1"] == nil {
+			t.Errorf("unexpected response: %+v", envelope)
+		}
+	})
+
+	t.Run("post", func(t *testing.T) {
+		post := doc.Paths["/user"]["post"]
+		if post == nil || post.Summary != "create user" || post.RequestBody == nil {
+			t.Fatalf("unexpected post operation: %s", w.Body.String())
+		}
+		body := doc.Components.Schemas["CreateUserReq"]
+		if body == nil || body.Properties["username"] == nil || body.Properties["tags"].Items.Type != "string" ||
+			body.Properties["Token"] != nil {
+			t.Errorf("unexpected request body schema: %+v", body)
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		w := serveRequest(server, http.MethodGet, "/openapi.yaml", nil)
+		if !strings.Contains(w.Body.String(), "openapi: 3.1.0\n") || !strings.Contains(w.Body.String(), `"200":`) {
+			t.Errorf("unexpected yaml document: %s", w.Body.String())
+		}
+	})
+
+	t.Run("ui", func(t *testing.T) {
+		w := serveRequest(server, http.MethodGet, "/openapi/ui", nil)
+		if !strings.Contains(w.Body.String(), `url: "/openapi.json"`) ||
+			!strings.Contains(w.Body.String(), `src="`+DefaultSwaggerUIAssets+`/swagger-ui-bundle.js"`) {
+			t.Errorf("unexpected swagger ui page: %s", w.Body.String())
+		}
+
+		server := New()
+		server.ServeOpenAPI(OpenAPIConfig{Path: "/docs", SwaggerUIAssets: "/static/swagger-ui/",
+			SwaggerUIIntegrity: SwaggerUIIntegrity{CSS: "sha384-css", JS: "sha384-js"}})
+		w = serveRequest(server, http.MethodGet, "/docs/ui", nil)
+		if !strings.Contains(w.Body.String(), `href="/static/swagger-ui/swagger-ui.css" crossorigin integrity="sha384-css"`) ||
+			!strings.Contains(w.Body.String(), `src="/static/swagger-ui/swagger-ui-bundle.js" crossorigin integrity="sha384-js"`) ||
+			!strings.Contains(w.Body.String(), `url: "/docs.json"`) {
+			t.Errorf("expect the self-hosted assets with the integrity, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestOpenAPIComponentName(t *testing.T) {
+	server := New()
+	server.GET("/users", Returns[Page[User]](), func() *Response {
+		return Ok()
+	})
+	server.GET("/pairs", Returns[Page[map[string]*User]](), func() *Response {
+		return Ok()
+	})
+
+	valid := regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	doc := server.OpenAPI(OpenAPIConfig{})
+	for name := range doc.Components.Schemas {
+		if !valid.MatchString(name) {
+			t.Errorf("invalid component name %s", name)
+		}
+	}
+	for path, expect := range map[string]string{"/users": "Page_easygin.User", "/pairs": "Page_map_string_easygin.User"} {
+		data := doc.Paths[path]["get"].Responses["200"].Content[ContentTypeJson].Schema.Properties["data"]
+		if data.Ref != "#/components/schemas/"+expect || doc.Components.Schemas[expect] == nil {
+			t.Errorf("%s: expect component %s, got %s", path, expect, data.Ref)
+		}
+	}
+}
@@ -4,21 +4,19 @@
 package easygin
 
 import (
-	"fmt"
+	"context"
+	"log/slog"
 	"os"
-	"path"
+	"path/filepath"
 	"runtime"
 	"runtime/pprof"
 	"runtime/trace"
+	"sort"
 	"sync/atomic"
 	"syscall"
 	"time"
 )
 
-const timeFormat = "0102150405"
-
-const DefaultMemProfileRate = 4096
-
 // 记录启动的次数
 var started uint32
 
@@ -32,10 +30,23 @@ func (s fakeStopper) Stop() {}
 
 type Profile struct {
 	closers []func()
+	logger  Logger
+	config  ProfileConfig
+	// files is the files of the profiles
+	files []string
+	// done is closed when the profile stops
+	done chan struct{}
 
 	stopped uint32
 }
 
+// profileSizeCheckInterval is the interval of checking the size of the files for MaxSize
+const profileSizeCheckInterval = 500 * time.Millisecond
+
+func (p *Profile) log(level slog.Level, msg string, args ...interface{}) {
+	p.logger.Log(context.Background(), level, msg, args...)
+}
+
 func (p *Profile) close() {
 	for _, fn := range p.closers {
 		fn()
@@ -44,75 +55,79 @@ func (p *Profile) close() {
 
 func (p *Profile) startBlockProfile() {
 	ppf := "block"
-	name := createDumpFile(ppf)
+	name := p.createDumpFile(ppf)
 	f, err := os.Create(name)
 	if err != nil {
-		elog.Printf("profile: could not create %s profile, err:%v", ppf, err)
+		p.log(slog.LevelError, "profile: could not create profile", "kind", ppf, "error", err)
+		p.discardFile(name)
 		return
 	}
 
-	runtime.SetBlockProfileRate(1)
-	elog.Printf("profile: %s profiling enabled, %s", ppf, name)
+	runtime.SetBlockProfileRate(p.config.BlockProfileRate)
+	p.log(slog.LevelInfo, "profile: profiling enabled", "kind", ppf, "rate", p.config.BlockProfileRate, "file", name)
 
 	p.closers = append(p.closers, func() {
 		_ = pprof.Lookup(ppf).WriteTo(f, 0)
 		_ = f.Close()
 		runtime.SetBlockProfileRate(0)
-		elog.Printf("profile: %s profiling disabled, %s", ppf, name)
+		p.log(slog.LevelInfo, "profile: profiling disabled", "kind", ppf, "file", name)
 	})
 }
 
 func (p *Profile) startCpuProfile() {
 	ppf := "cpu"
-	name := createDumpFile(ppf)
+	name := p.createDumpFile(ppf)
 	f, err := os.Create(name)
 	if err != nil {
-		elog.Printf("profile: could not create %s profile, err:%v", ppf, err)
+		p.log(slog.LevelError, "profile: could not create profile", "kind", ppf, "error", err)
+		p.discardFile(name)
 		return
 	}
 
 	_ = pprof.StartCPUProfile(f)
-	elog.Printf("profile: %s profiling enabled, %s", ppf, name)
+	p.log(slog.LevelInfo, "profile: profiling enabled", "kind", ppf, "file", name)
 
 	p.closers = append(p.closers, func() {
 		pprof.StopCPUProfile()
 		_ = f.Close()
-		elog.Printf("profile: %s profiling disabled, %s", ppf, name)
+		p.log(slog.LevelInfo, "profile: profiling disabled", "kind", ppf, "file", name)
 	})
 }
 
 func (p *Profile) startMemProfile() {
 	ppf := "mem"
-	name := createDumpFile(ppf)
+	name := p.createDumpFile(ppf)
 	f, err := os.Create(name)
 	if err != nil {
-		elog.Printf("profile: could not create %s profile, err:%v", ppf, err)
+		p.log(slog.LevelError, "profile: could not create profile", "kind", ppf, "error", err)
+		p.discardFile(name)
 		return
 	}
 
 	old := runtime.MemProfileRate
-	runtime.MemProfileRate = DefaultMemProfileRate
-	elog.Printf("profile: %s profiling enabled (rate %d), %s", ppf, DefaultMemProfileRate, name)
+	runtime.MemProfileRate = p.config.MemProfileRate
+	p.log(slog.LevelInfo, "profile: profiling enabled", "kind", ppf, "rate", p.config.MemProfileRate, "file", name)
 
 	p.closers = append(p.closers, func() {
 		pprof.Lookup("heap").WriteTo(f, 0)
 		_ = f.Close()
 		runtime.MemProfileRate = old
-		elog.Printf("profile: %s profiling disabled, %s", ppf, name)
+		p.log(slog.LevelInfo, "profile: profiling disabled", "kind", ppf, "file", name)
 	})
 }
 
 func (p *Profile) startMutexProfile() {
 	ppf := "mutex"
-	name := createDumpFile(ppf)
+	name := p.createDumpFile(ppf)
 	f, err := os.Create(name)
 	if err != nil {
-		elog.Printf("profile: could not create %s profile, err:%v", ppf, err)
+		p.log(slog.LevelError, "profile: could not create profile", "kind", ppf, "error", err)
+		p.discardFile(name)
 		return
 	}
 
-	runtime.SetMutexProfileFraction(1)
-	elog.Printf("profile: %s profiling enabled, %s", ppf, name)
+	runtime.SetMutexProfileFraction(p.config.MutexProfileFraction)
+	p.log(slog.LevelInfo, "profile: profiling enabled", "kind", ppf, "fraction", p.config.MutexProfileFraction, "file", name)
 
 	p.closers = append(p.closers, func() {
 		if mp := pprof.Lookup(ppf); mp != nil {
@@ -120,83 +135,220 @@ func (p *Profile) startMutexProfile() {
 		}
 		_ = f.Close()
 		runtime.SetMutexProfileFraction(0)
-		elog.Printf("profile: %s profiling disabled, %s", ppf, name)
+		p.log(slog.LevelInfo, "profile: profiling disabled", "kind", ppf, "file", name)
 	})
 }
 
 func (p *Profile) startThreadCreateProfile() {
 	ppf := "threadcreate"
-	name := createDumpFile(ppf)
+	name := p.createDumpFile(ppf)
 	f, err := os.Create(name)
 	if err != nil {
-		elog.Printf("profile: could not create %s profile, err:%v", ppf, err)
+		p.log(slog.LevelError, "profile: could not create profile", "kind", ppf, "error", err)
+		p.discardFile(name)
 		return
 	}
 
-	elog.Printf("profile: %s profiling enabled, %s", ppf, name)
+	p.log(slog.LevelInfo, "profile: profiling enabled", "kind", ppf, "file", name)
 
 	p.closers = append(p.closers, func() {
 		if mp := pprof.Lookup(ppf); mp != nil {
 			_ = mp.WriteTo(f, 0)
 		}
 		_ = f.Close()
-		elog.Printf("profile: %s profiling disabled, %s", ppf, name)
+		p.log(slog.LevelInfo, "profile: profiling disabled", "kind", ppf, "file", name)
 	})
 }
 
 func (p *Profile) startTraceProfile() {
 	ppf := "trace"
-	name := createDumpFile(ppf)
+	name := p.createDumpFile(ppf)
 	f, err := os.Create(name)
 	if err != nil {
-		elog.Printf("profile: could not create %s profile, err:%v", ppf, err)
+		p.log(slog.LevelError, "profile: could not create profile", "kind", ppf, "error", err)
+		p.discardFile(name)
 		return
 	}
 
 	if err = trace.Start(f); err != nil {
-		elog.Printf("profile: could not start trace: %v", err)
+		p.log(slog.LevelError, "profile: could not start trace", "error", err)
+		_ = f.Close()
+		_ = os.Remove(name)
+		p.discardFile(name)
 		return
 	}
 
-	elog.Printf("profile: %s profiling enabled, %s", ppf, name)
+	p.log(slog.LevelInfo, "profile: profiling enabled", "kind", ppf, "file", name)
 
 	p.closers = append(p.closers, func() {
 		trace.Stop()
-		elog.Printf("profile: %s profiling disabled, %s", ppf, name)
+		_ = f.Close()
+		p.log(slog.LevelInfo, "profile: profiling disabled", "kind", ppf, "file", name)
 	})
 }
 
 func (p *Profile) Stop() {
+	p.stop("stopped")
+}
+
+// stop stop the profiles and log the files with the reason
+func (p *Profile) stop(reason string) {
 	if !atomic.CompareAndSwapUint32(&p.stopped, 0, 1) {
 		return
 	}
 	p.close()
+	p.removeOldFiles()
 	atomic.StoreUint32(&started, 0)
+	p.log(slog.LevelInfo, "profile: profiling session finished", "reason", reason, "files", p.files)
+	close(p.done)
+}
+
+// Stopped report whether the profile is stopped, by Stop or by the limits of ProfileConfig
+func (p *Profile) Stopped() bool {
+	return atomic.LoadUint32(&p.stopped) == 1
+}
+
+// Done return the channel closed after the profile stops and the files are written
+func (p *Profile) Done() <-chan struct{} {
+	return p.done
+}
+
+// Files return the files of the profiles
+func (p *Profile) Files() []string {
+	return p.files
+}
+
+// watch stop the profile after MaxDuration or when the size of the files reaches MaxSize
+func (p *Profile) watch() {
+	var timeout, tick <-chan time.Time
+	if p.config.MaxDuration > 0 {
+		timer := time.NewTimer(p.config.MaxDuration)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	if p.config.MaxSize > 0 {
+		ticker := time.NewTicker(profileSizeCheckInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-timeout:
+			p.stop("max duration reached")
+			return
+		case <-tick:
+			if p.size() >= p.config.MaxSize {
+				p.stop("max size reached")
+				return
+			}
+		}
+	}
+}
+
+// size return the total size of the files
+func (p *Profile) size() int64 {
+	var size int64
+	for _, file := range p.files {
+		if info, err := os.Stat(file); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
 }
 
 func StartProfile() Stopper {
+	return startProfile(defaultLogger(), ProfileConfig{})
+}
+
+// StartProfileFor start all the profiles, they stop themselves after d, etc.: a 30-second capture
+func StartProfileFor(d time.Duration) Stopper {
+	return startProfile(defaultLogger(), ProfileConfig{MaxDuration: d})
+}
+
+// StartProfileWithConfig start the profiles selected by config
+func StartProfileWithConfig(config ProfileConfig) Stopper {
+	return startProfile(defaultLogger(), config)
+}
+
+func startProfile(logger Logger, config ProfileConfig) Stopper {
 	if !atomic.CompareAndSwapUint32(&started, 0, 1) {
-		elog.Printf("profile: Start() already called")
+		logger.Log(context.Background(), slog.LevelWarn, "profile: Start() already called")
 		return fakeStopper{}
 	}
 
-	var prof Profile
-	prof.startBlockProfile()
-	prof.startCpuProfile()
-	prof.startMemProfile()
-	prof.startMutexProfile()
-	prof.startTraceProfile()
-	prof.startThreadCreateProfile()
+	prof := Profile{logger: logger, config: config.withDefaults(), done: make(chan struct{})}
+	if err := prof.config.validate(); err != nil {
+		prof.log(slog.LevelError, "profile: invalid config", "error", err)
+		atomic.StoreUint32(&started, 0)
+		return fakeStopper{}
+	}
+	if err := os.MkdirAll(prof.config.Dir, 0o755); err != nil {
+		prof.log(slog.LevelError, "profile: could not create directory", "dir", prof.config.Dir, "error", err)
+	}
+	starters := map[string]func(){
+		BlockProfile:        prof.startBlockProfile,
+		CPUProfile:          prof.startCpuProfile,
+		MemProfile:          prof.startMemProfile,
+		MutexProfile:        prof.startMutexProfile,
+		TraceProfile:        prof.startTraceProfile,
+		ThreadCreateProfile: prof.startThreadCreateProfile,
+	}
+	for _, kind := range prof.config.Profiles {
+		if start, ok := starters[kind]; ok {
+			start()
+		} else {
+			prof.log(slog.LevelWarn, "profile: unknown profile", "kind", kind)
+		}
+	}
+	if prof.config.MaxDuration > 0 || prof.config.MaxSize > 0 {
+		go prof.watch()
+	}
 
 	return &prof
 }
 
-func createDumpFile(kind string) string {
-	command := path.Base(os.Args[0])
-	pid := syscall.Getpid()
+func (p *Profile) createDumpFile(kind string) string {
+	name := p.config.fileName(kind, syscall.Getpid(), time.Now())
+	p.files = append(p.files, name)
+	return name
+}
 
-	p := path.Join(os.TempDir(), fmt.Sprintf("%s-%d-%s-%s.pprof",
-		command, pid, kind, time.Now().Format(timeFormat)))
+// discardFile remove the file from the files of the profile, it is not written
+func (p *Profile) discardFile(name string) {
+	for i, file := range p.files {
+		if file == name {
+			p.files = append(p.files[:i], p.files[i+1:]...)
+			return
+		}
+	}
+}
 
-	return p
+// removeOldFiles remove the files of each kind except the newest Retention ones
+func (p *Profile) removeOldFiles() {
+	if p.config.Retention <= 0 {
+		return
+	}
+	for _, kind := range p.config.Profiles {
+		files, err := filepath.Glob(p.config.fileName(kind, 0, time.Time{}))
+		if err != nil || len(files) <= p.config.Retention {
+			continue
+		}
+		modTimes := make(map[string]time.Time, len(files))
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil {
+				modTimes[file] = info.ModTime()
+			}
+		}
+		sort.Slice(files, func(i, j int) bool {
+			return modTimes[files[i]].After(modTimes[files[j]])
+		})
+		for _, file := range files[p.config.Retention:] {
+			if err = os.Remove(file); err == nil {
+				p.log(slog.LevelInfo, "profile: old profile removed", "kind", kind, "file", file)
+			}
+		}
+	}
 }
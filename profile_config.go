@@ -0,0 +1,125 @@
+package easygin
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elliotchance/pie/v2"
+)
+
+const timeFormat = "0102150405"
+
+const DefaultMemProfileRate = 4096
+
+// the kinds of profiles
+const (
+	BlockProfile        = "block"
+	CPUProfile          = "cpu"
+	MemProfile          = "mem"
+	MutexProfile        = "mutex"
+	TraceProfile        = "trace"
+	ThreadCreateProfile = "threadcreate"
+)
+
+// DefaultProfileNameTemplate is the default file name template of the profiles
+const DefaultProfileNameTemplate = "{command}-{pid}-{kind}-{time}.pprof"
+
+// ProfileConfig configures the profiling session started by StartProfileWithConfig and the SIGUSR1 toggle
+type ProfileConfig struct {
+	// Profiles is the kinds of profiles to collect, default is all of them
+	Profiles []string
+	// BlockProfileRate is the rate of runtime.SetBlockProfileRate, default is 1
+	BlockProfileRate int
+	// MutexProfileFraction is the fraction of runtime.SetMutexProfileFraction, default is 1
+	MutexProfileFraction int
+	// MemProfileRate is the runtime.MemProfileRate during profiling, default is DefaultMemProfileRate
+	MemProfileRate int
+	// Dir is the directory of the files, default is os.TempDir()
+	Dir string
+	// NameTemplate is the name of the files, {command}, {pid}, {kind} and {time} are replaced,
+	// it must contain {kind} so that the kinds are written to different files, default is DefaultProfileNameTemplate
+	NameTemplate string
+	// Retention is the number of the files of each kind kept in Dir, the older files matching NameTemplate
+	// are removed after profiling, 0 keeps all of them
+	Retention int
+	// MaxDuration stops the profiling after it, 0 means no limit
+	MaxDuration time.Duration
+	// MaxSize stops the profiling when the size of the trace reaches it in bytes, 0 means no limit.
+	// only the trace grows while profiling, the other profiles such as cpu and mem are written when they stop,
+	// so MaxSize needs TraceProfile in Profiles. the size is checked periodically
+	MaxSize int64
+}
+
+func (c ProfileConfig) withDefaults() ProfileConfig {
+	if len(c.Profiles) == 0 {
+		c.Profiles = []string{BlockProfile, CPUProfile, MemProfile, MutexProfile, TraceProfile, ThreadCreateProfile}
+	}
+	if c.BlockProfileRate <= 0 {
+		c.BlockProfileRate = 1
+	}
+	if c.MutexProfileFraction <= 0 {
+		c.MutexProfileFraction = 1
+	}
+	if c.MemProfileRate <= 0 {
+		c.MemProfileRate = DefaultMemProfileRate
+	}
+	if c.Dir == "" {
+		c.Dir = os.TempDir()
+	}
+	if c.NameTemplate == "" {
+		c.NameTemplate = DefaultProfileNameTemplate
+	}
+	return c
+}
+
+// validate check the config after withDefaults
+func (c *ProfileConfig) validate() error {
+	if !strings.Contains(c.NameTemplate, "{kind}") {
+		return errors.New("profile: NameTemplate must contain {kind}")
+	}
+	if c.MaxSize > 0 && !pie.Contains(c.Profiles, TraceProfile) {
+		return errors.New("profile: MaxSize needs TraceProfile, the other profiles are written when they stop")
+	}
+	return nil
+}
+
+// fileName return the path of the file of the kind, the placeholders not given are kept as "*"
+// so that the name can be used as a glob pattern
+func (c *ProfileConfig) fileName(kind string, pid int, t time.Time) string {
+	pidStr, timeStr := "*", "*"
+	if pid > 0 {
+		pidStr = strconv.Itoa(pid)
+	}
+	if !t.IsZero() {
+		timeStr = t.Format(timeFormat)
+	}
+	name := strings.NewReplacer(
+		"{command}", path.Base(os.Args[0]),
+		"{pid}", pidStr,
+		"{kind}", kind,
+		"{time}", timeStr,
+	).Replace(c.NameTemplate)
+	return path.Join(c.Dir, name)
+}
+
+// SetProfileConfig set the config of the profiling toggled by SIGUSR1 of the default handlers
+func (m *SignalManager) SetProfileConfig(config ProfileConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.profileConfig = config
+}
+
+func (m *SignalManager) getProfileConfig() ProfileConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.profileConfig
+}
+
+// SetProfileConfig set the config of the profiling toggled by SIGUSR1, it is set to the SignalManager of EasyGin
+func (e *EasyGin) SetProfileConfig(config ProfileConfig) {
+	e.SignalManager().SetProfileConfig(config)
+}
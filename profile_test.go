@@ -0,0 +1,146 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package easygin
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/trace"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfileConfig(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profiles")
+	config := ProfileConfig{
+		Profiles:       []string{CPUProfile, MemProfile},
+		MemProfileRate: 1024,
+		Dir:            dir,
+		NameTemplate:   "app-{kind}-{pid}-{time}.prof",
+		Retention:      2,
+	}
+
+	// the old files of the kinds are removed except the newest ones
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"app-cpu-1-0101000000.prof", "app-cpu-2-0101000000.prof", "app-mutex-1-0101000000.prof", "other.prof"} {
+		file := filepath.Join(dir, name)
+		_ = os.WriteFile(file, nil, 0o600)
+		_ = os.Chtimes(file, time.Now().Add(-time.Duration(i+1)*time.Hour), time.Now().Add(-time.Duration(i+1)*time.Hour))
+	}
+
+	oldRate := runtime.MemProfileRate
+	stopper := StartProfileWithConfig(config)
+	if runtime.MemProfileRate != 1024 {
+		t.Errorf("expect MemProfileRate 1024, got %d", runtime.MemProfileRate)
+	}
+	files := stopper.(*Profile).Files()
+	stopper.Stop()
+	if runtime.MemProfileRate != oldRate {
+		t.Errorf("expect MemProfileRate to be restored, got %d", runtime.MemProfileRate)
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("expect cpu and mem profiles, got %v", files)
+	}
+	for _, file := range files {
+		if info, err := os.Stat(file); err != nil || info.Size() == 0 || !strings.HasPrefix(filepath.Base(file), "app-") {
+			t.Errorf("unexpected profile %s %v", file, err)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	expect := []string{filepath.Base(files[0]), "app-cpu-1-0101000000.prof", "app-mutex-1-0101000000.prof", filepath.Base(files[1]), "other.prof"}
+	sort.Strings(expect)
+	if strings.Join(names, ",") != strings.Join(expect, ",") {
+		t.Errorf("expect files %v, got %v", expect, names)
+	}
+}
+
+func TestProfileLimits(t *testing.T) {
+	dir := t.TempDir()
+
+	// the profile stops itself after MaxDuration
+	stopper := StartProfileWithConfig(ProfileConfig{Profiles: []string{CPUProfile, MemProfile}, Dir: dir, MaxDuration: 50 * time.Millisecond})
+	profile := stopper.(*Profile)
+	select {
+	case <-profile.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("expect the profile to stop after MaxDuration")
+	}
+	for _, file := range profile.Files() {
+		if info, err := os.Stat(file); err != nil || info.Size() == 0 {
+			t.Errorf("unexpected profile %s %v", file, err)
+		}
+	}
+	// a new profile can be started after it
+	stopper = StartProfileWithConfig(ProfileConfig{Profiles: []string{TraceProfile}, Dir: dir, MaxSize: 1})
+	profile = stopper.(*Profile)
+	defer profile.Stop()
+
+	// the profile stops itself when the trace reaches MaxSize
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				done := make(chan struct{})
+				go close(done)
+				<-done
+			}
+		}
+	}()
+	select {
+	case <-profile.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expect the profile to stop after MaxSize")
+	}
+	if !profile.Stopped() {
+		t.Error("expect the profile to be stopped")
+	}
+}
+
+func TestProfileErrors(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "app-other.prof")
+	_ = os.WriteFile(other, nil, 0o600)
+
+	// the kinds would be written to the same file and removed by the retention of each other
+	stopper := StartProfileWithConfig(ProfileConfig{Profiles: []string{CPUProfile}, Dir: dir, NameTemplate: "app-{time}.prof", Retention: 1})
+	stopper.Stop()
+	if _, ok := stopper.(fakeStopper); !ok {
+		t.Errorf("expect the config without {kind} to be rejected, got %T", stopper)
+	}
+
+	// the cpu profile is written when it stops, MaxSize can not limit it
+	stopper = StartProfileWithConfig(ProfileConfig{Profiles: []string{CPUProfile}, Dir: dir, MaxSize: 1})
+	stopper.Stop()
+	if _, ok := stopper.(fakeStopper); !ok {
+		t.Errorf("expect MaxSize without the trace to be rejected, got %T", stopper)
+	}
+
+	// the trace file is removed if the trace can not be started
+	if err := trace.Start(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	stopper = StartProfileWithConfig(ProfileConfig{Profiles: []string{TraceProfile}, Dir: dir})
+	stopper.Stop()
+	trace.Stop()
+	entries, _ := os.ReadDir(dir)
+	if files := stopper.(*Profile).Files(); len(files) != 0 || len(entries) != 1 {
+		t.Errorf("expect the trace file to be removed, got %v %v", files, entries)
+	}
+}
@@ -0,0 +1,332 @@
+package easygin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitAlgorithm is the algorithm of the rate limiter
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket allow bursts of Limit requests, the tokens are refilled at Limit per Window
+	TokenBucket RateLimitAlgorithm = iota
+	// SlidingWindow allow Limit requests in any Window, it is approximated by the counters of the current
+	// and the previous window
+	SlidingWindow
+)
+
+const TooManyRequestsCode = 429
+
+var RespTooManyRequests = RespError(&RespErrorImpl{
+	Codee:    TooManyRequestsCode,
+	Messagee: "too many requests",
+})
+
+// RateLimitKey is the key the requests are limited by, requests with an empty key are not limited
+type RateLimitKey struct {
+	key func(ctx *gin.Context) string
+	// afterBind is true if the key is read from the bound request
+	afterBind bool
+}
+
+// KeyFunc limit the requests by the key returned by f
+func KeyFunc(f func(ctx *gin.Context) string) RateLimitKey {
+	return RateLimitKey{key: f}
+}
+
+// KeyByIP limit the requests by the client ip
+func KeyByIP() RateLimitKey {
+	return KeyFunc(func(ctx *gin.Context) string {
+		return ctx.ClientIP()
+	})
+}
+
+// KeyByHeader limit the requests by the value of the header
+func KeyByHeader(header string) RateLimitKey {
+	return KeyFunc(func(ctx *gin.Context) string {
+		return ctx.GetHeader(header)
+	})
+}
+
+// KeyByField limit the requests by the field of the bound request, field is the name of the field
+// or its json, form, uri or header tag. the limiter is checked after the parameters are bound
+func KeyByField(field string) RateLimitKey {
+	return RateLimitKey{afterBind: true, key: func(ctx *gin.Context) string {
+		v := reflect.ValueOf(BoundRequest(ctx))
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return ""
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return ""
+		}
+		if i := fieldIndex(v.Type(), field); i >= 0 {
+			return fmt.Sprint(v.Field(i).Interface())
+		}
+		return ""
+	}}
+}
+
+var fieldIndexes sync.Map // [2]interface{}{reflect.Type, name} --> int
+
+// fieldIndex return the index of the field by its name or tag, -1 if not found
+func fieldIndex(t reflect.Type, name string) int {
+	key := [2]interface{}{t, name}
+	if i, ok := fieldIndexes.Load(key); ok {
+		return i.(int)
+	}
+	index := -1
+	for i := 0; i < t.NumField() && index < 0; i++ {
+		f := t.Field(i)
+		if f.Name == name {
+			index = i
+			break
+		}
+		for _, tag := range []string{"json", "form", "uri", "header"} {
+			if tagName(f.Tag.Get(tag)) == name {
+				index = i
+				break
+			}
+		}
+	}
+	fieldIndexes.Store(key, index)
+	return index
+}
+
+// RateLimitState is the state of a key kept by RateLimitStore
+type RateLimitState struct {
+	// Tokens and Last are the state of TokenBucket
+	Tokens float64   `json:"tokens,omitempty"`
+	Last   time.Time `json:"last,omitempty"`
+	// Start, Previous and Current are the state of SlidingWindow
+	Start    time.Time `json:"start,omitempty"`
+	Previous int       `json:"previous,omitempty"`
+	Current  int       `json:"current,omitempty"`
+}
+
+// RateLimitStore keeps the states of the keys, it can be implemented by redis for multiple instances
+type RateLimitStore interface {
+	// Update call update with the state of key atomically and save the returned state,
+	// the state of a new or expired key is zero, the saved state expires after ttl
+	Update(ctx context.Context, key string, ttl time.Duration, update func(state RateLimitState) RateLimitState) error
+}
+
+// MemoryRateLimitStore keeps the states in memory, the expired states are removed periodically
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	states    map[string]memoryRateLimitState
+	lastSweep time.Time
+}
+
+type memoryRateLimitState struct {
+	RateLimitState
+	expire time.Time
+}
+
+const rateLimitSweepInterval = time.Minute
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{states: make(map[string]memoryRateLimitState), lastSweep: time.Now()}
+}
+
+func (s *MemoryRateLimitStore) Update(ctx context.Context, key string, ttl time.Duration, update func(state RateLimitState) RateLimitState) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > rateLimitSweepInterval {
+		for k, state := range s.states {
+			if now.After(state.expire) {
+				delete(s.states, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	state, ok := s.states[key]
+	if !ok || now.After(state.expire) {
+		state = memoryRateLimitState{}
+	}
+	s.states[key] = memoryRateLimitState{RateLimitState: update(state.RateLimitState), expire: now.Add(ttl)}
+	return nil
+}
+
+type RateLimitConfig struct {
+	Algorithm RateLimitAlgorithm
+	// Limit is the number of requests allowed in Window
+	Limit  int
+	Window time.Duration
+	// Key is the key the requests are limited by, default is KeyByIP
+	Key RateLimitKey
+	// Store keeps the states of the keys, default is a MemoryRateLimitStore
+	Store RateLimitStore
+	// Status is the http status of the rejection, default is 429
+	Status int
+	// Error is the RespError of the rejection, default is RespTooManyRequests
+	Error RespError
+}
+
+// rateLimitResult is the result of taking a request
+type rateLimitResult struct {
+	allowed    bool
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// RateLimit limit the requests of the route, it is checked after the rate limits of the group
+func RateLimit(config RateLimitConfig) RouteOption {
+	return routeOptionFunc(func(rt *route) {
+		rt.guards = append(rt.guards, config.guard(rt.engine, rt.method+" "+rt.path))
+	})
+}
+
+// RateLimit limit the requests of the routes registered in the group after it, every route is limited separately
+func (r *RouterGroup) RateLimit(config RateLimitConfig) {
+	r.guards = append(r.guards, config.guard(r.engine, ""))
+}
+
+// RateLimit limit the requests of the routes registered after it, see RouterGroup.RateLimit
+func (e *EasyGin) RateLimit(config RateLimitConfig) {
+	e.root.RateLimit(config)
+}
+
+func (c RateLimitConfig) withDefaults() *RateLimitConfig {
+	if c.Limit <= 0 || c.Window <= 0 {
+		panic("the limit and window of rate limit must be positive")
+	}
+	if c.Key.key == nil {
+		c.Key = KeyByIP()
+	}
+	if c.Store == nil {
+		c.Store = NewMemoryRateLimitStore()
+	}
+	if c.Status == 0 {
+		c.Status = http.StatusTooManyRequests
+	}
+	if c.Error == nil {
+		c.Error = RespTooManyRequests
+	}
+	return &c
+}
+
+// guard create the guard of the limiter, the keys are prefixed with route, or the route of the request if it is empty
+func (c RateLimitConfig) guard(engine *EasyGin, route string) guard {
+	limiter := c.withDefaults()
+	return guard{afterBind: limiter.Key.afterBind, check: func(ctx *gin.Context) *Response {
+		if route == "" {
+			return limiter.check(ctx, engine, ctx.Request.Method+" "+ctx.FullPath())
+		}
+		return limiter.check(ctx, engine, route)
+	}}
+}
+
+// check take a request of the key, the RateLimit headers are set, it returns the rejection if not allowed.
+// the request is allowed if the store fails
+func (c *RateLimitConfig) check(ctx *gin.Context, engine *EasyGin, route string) *Response {
+	key := c.Key.key(ctx)
+	if key == "" {
+		return nil
+	}
+
+	var result rateLimitResult
+	now := time.Now()
+	// the state is kept for two windows, which is needed by SlidingWindow
+	err := c.Store.Update(ctx.Request.Context(), route+"|"+key, 2*c.Window, func(state RateLimitState) RateLimitState {
+		var next RateLimitState
+		if c.Algorithm == SlidingWindow {
+			next, result = c.slidingWindow(state, now)
+		} else {
+			next, result = c.tokenBucket(state, now)
+		}
+		return next
+	})
+	if err != nil {
+		engine.Logger().Log(ctx.Request.Context(), slog.LevelError, "rate limit store failed", "route", route, "error", err)
+		return nil
+	}
+
+	ctx.Header("RateLimit-Limit", strconv.Itoa(c.Limit))
+	ctx.Header("RateLimit-Remaining", strconv.Itoa(result.remaining))
+	ctx.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.reset)))
+	if result.allowed {
+		return nil
+	}
+	ctx.Header("Retry-After", strconv.Itoa(ceilSeconds(result.retryAfter)))
+	return FailStatus(c.Status, c.Error)
+}
+
+func (c *RateLimitConfig) tokenBucket(state RateLimitState, now time.Time) (RateLimitState, rateLimitResult) {
+	capacity := float64(c.Limit)
+	// tokens refilled per second
+	rate := capacity / c.Window.Seconds()
+	if state.Last.IsZero() {
+		state.Tokens = capacity
+	} else {
+		state.Tokens = math.Min(capacity, state.Tokens+now.Sub(state.Last).Seconds()*rate)
+	}
+	state.Last = now
+
+	var result rateLimitResult
+	if state.Tokens >= 1 {
+		state.Tokens--
+		result.allowed = true
+	} else {
+		result.retryAfter = time.Duration((1 - state.Tokens) / rate * float64(time.Second))
+	}
+	result.remaining = int(state.Tokens)
+	result.reset = time.Duration((capacity - state.Tokens) / rate * float64(time.Second))
+	return state, result
+}
+
+func (c *RateLimitConfig) slidingWindow(state RateLimitState, now time.Time) (RateLimitState, rateLimitResult) {
+	start := now.Truncate(c.Window)
+	switch {
+	case state.Start.Equal(start):
+	case state.Start.Add(c.Window).Equal(start):
+		state.Previous, state.Current = state.Current, 0
+	default:
+		state.Previous, state.Current = 0, 0
+	}
+	state.Start = start
+
+	// the requests of the previous window are weighted by its overlap with the sliding window
+	elapsed := now.Sub(start)
+	weight := 1 - float64(elapsed)/float64(c.Window)
+	count := float64(state.Previous)*weight + float64(state.Current)
+
+	var result rateLimitResult
+	if count+1 <= float64(c.Limit) {
+		state.Current++
+		count++
+		result.allowed = true
+	} else if state.Current+1 > c.Limit || state.Previous == 0 {
+		result.retryAfter = c.Window - elapsed
+	} else {
+		// wait until the weight of the previous window is small enough
+		need := 1 - float64(c.Limit-state.Current-1)/float64(state.Previous)
+		result.retryAfter = time.Duration(need*float64(c.Window)) - elapsed
+	}
+	result.remaining = int(math.Max(0, float64(c.Limit)-count))
+	result.reset = c.Window - elapsed
+	return state, result
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,109 @@
+package easygin
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// jsonRateLimitStore is a stand-in of a redis store, the states are serialized
+type jsonRateLimitStore struct {
+	mu     sync.Mutex
+	states map[string][]byte
+}
+
+func (s *jsonRateLimitStore) Update(ctx context.Context, key string, ttl time.Duration, update func(state RateLimitState) RateLimitState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var state RateLimitState
+	if data, ok := s.states[key]; ok {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(update(state))
+	if err != nil {
+		return err
+	}
+	s.states[key] = data
+	return nil
+}
+
+func TestRateLimit(t *testing.T) {
+	type Order struct {
+		UserID int `form:"user_id"`
+	}
+
+	store := &jsonRateLimitStore{states: make(map[string][]byte)}
+	server := New()
+	group := server.Group("/api")
+	group.RateLimit(RateLimitConfig{Algorithm: SlidingWindow, Limit: 3, Window: time.Hour, Key: KeyByHeader("X-Token")})
+	group.GET("/user", RateLimit(RateLimitConfig{Limit: 2, Window: time.Hour, Store: store}), func() *Response {
+		return Ok()
+	})
+	// the rate limit declared before Auth limits the failed authentications
+	group.GET("/login", RateLimit(RateLimitConfig{Limit: 2, Window: time.Hour}), Auth(Basic(BasicConfig{Users: map[string]string{"admin": "pass"}})),
+		func(user *BasicPrincipal) *Response {
+			return OkData(user.Username)
+		})
+	group.GET("/order", RateLimit(RateLimitConfig{Limit: 1, Window: time.Hour, Key: KeyByField("user_id")}), func(order *Order) *Response {
+		return OkData(order.UserID)
+	})
+
+	t.Run("token bucket", func(t *testing.T) {
+		// the token bucket of the route allows 2 requests of an ip
+		for i, status := range []int{200, 200, 429} {
+			w := serveRequest(server, http.MethodGet, "/api/user", nil, "X-Token", strconv.Itoa(i))
+			if w.Code != status {
+				t.Fatalf("request %d: expect %d, got %d %s", i, status, w.Code, w.Body.String())
+			}
+			if status == 429 {
+				if body := `{"data":null,"code":429,"message":"too many requests"}`; w.Body.String() != body {
+					t.Errorf("expect %s, got %s", body, w.Body.String())
+				}
+				if retry, _ := strconv.Atoi(w.Header().Get("Retry-After")); retry < 1700 || retry > 1800 {
+					t.Errorf("unexpected Retry-After %q", w.Header().Get("Retry-After"))
+				}
+			} else if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != strconv.Itoa(1-i) {
+				t.Errorf("unexpected RateLimit headers: %v", w.Header())
+			}
+		}
+		if len(store.states) != 1 {
+			t.Errorf("expect the state in the store, got %v", store.states)
+		}
+	})
+
+	t.Run("sliding window", func(t *testing.T) {
+		// the sliding window of the group allows 3 requests of a token in every route
+		for i, status := range []int{200, 200, 200, 429} {
+			if w := serveRequest(server, http.MethodGet, "/api/order?user_id="+strconv.Itoa(i), nil, "X-Token", "token"); w.Code != status {
+				t.Fatalf("request %d: expect %d, got %d %s", i, status, w.Code, w.Body.String())
+			}
+		}
+	})
+
+	t.Run("bound field", func(t *testing.T) {
+		// the bound field of the route allows 1 request of a user
+		if w := serveRequest(server, http.MethodGet, "/api/order?user_id=0", nil, "X-Token", "other"); w.Code != 429 || w.Header().Get("Retry-After") == "" {
+			t.Errorf("expect the request of the user to be limited, got %d %v", w.Code, w.Header())
+		}
+	})
+
+	t.Run("failed authentication", func(t *testing.T) {
+		basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("admin:wrong"))
+		for i, status := range []int{401, 401, 429} {
+			w := serveRequest(server, http.MethodGet, "/api/login", nil, "Authorization", basic, "X-Token", "login")
+			if w.Code != status {
+				t.Fatalf("request %d: expect %d, got %d %s", i, status, w.Code, w.Body.String())
+			}
+			if w.Header().Get("RateLimit-Limit") != "2" {
+				t.Errorf("request %d: expect the RateLimit headers, got %v", i, w.Header())
+			}
+		}
+	})
+}
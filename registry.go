@@ -0,0 +1,260 @@
+package easygin
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// binding sources of the handler parameters
+const (
+	SourceContext = "context"
+	SourceQuery   = "query"
+	SourceBody    = "body"
+	SourceURI     = "uri"
+	SourceHeader  = "header"
+	// SourcePrincipal is the principal injected by Auth
+	SourcePrincipal = "principal"
+)
+
+// RouteInfo describes a route registered through EasyGin or RouterGroup
+type RouteInfo struct {
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Handler string      `json:"handler"`
+	Params  []ParamInfo `json:"params"`
+	Summary string      `json:"summary,omitempty"`
+	// Response is the type of the response data declared by Returns, empty if not declared
+	Response string `json:"response,omitempty"`
+	// Errors is the RespError declared by Errors
+	Errors []ErrorInfo `json:"errors,omitempty"`
+	// Authorization is the policies declared by Authorize in the order they are checked
+	Authorization []string `json:"authorization,omitempty"`
+
+	params   []reflect.Type
+	response reflect.Type
+	// authenticated is true if the route is authenticated by Auth, the principals are injected
+	authenticated bool
+}
+
+// ParamInfo describes a parameter of the handler and where it is bound from
+type ParamInfo struct {
+	Type string `json:"type"`
+	// Sources is where the parameter is bound from, a struct without body is bound from query,
+	// otherwise it is bound from body, fields with uri or header tag are bound from uri or header
+	Sources []string    `json:"sources"`
+	Fields  []FieldInfo `json:"fields,omitempty"`
+}
+
+// FieldInfo describes a field of a struct parameter
+type FieldInfo struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	JSON     string `json:"json,omitempty"`
+	Form     string `json:"form,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Header   string `json:"header,omitempty"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// RouteOption configures a route, it can be passed among the handlers when registering the route,
+// etc.: e.GET("/user/:id", easygin.Returns[User](), getUser)
+type RouteOption interface {
+	applyRoute(rt *route)
+}
+
+type routeOptionFunc func(rt *route)
+
+func (f routeOptionFunc) applyRoute(rt *route) {
+	f(rt)
+}
+
+// Returns declare the type of the data of the *Response returned by the handler
+func Returns[T any]() RouteOption {
+	return routeOptionFunc(func(rt *route) {
+		rt.info.response = reflect.TypeOf((*T)(nil)).Elem()
+		rt.info.Response = rt.info.response.String()
+	})
+}
+
+// Summary set the summary of the route which is shown in the OpenAPI document
+func Summary(summary string) RouteOption {
+	return routeOptionFunc(func(rt *route) {
+		rt.info.Summary = summary
+	})
+}
+
+type registry struct {
+	mu     sync.RWMutex
+	routes []*RouteInfo
+}
+
+func (r *registry) add(info *RouteInfo) {
+	r.mu.Lock()
+	r.routes = append(r.routes, info)
+	r.mu.Unlock()
+}
+
+// list return a copy of the registered routes sorted by path and method
+func (r *registry) list() []RouteInfo {
+	r.mu.RLock()
+	routes := make([]RouteInfo, 0, len(r.routes))
+	for _, info := range r.routes {
+		routes = append(routes, *info)
+	}
+	r.mu.RUnlock()
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// RegisteredRoutes return the routes registered through EasyGin and RouterGroup
+func (e *EasyGin) RegisteredRoutes() []RouteInfo {
+	e.Prepare()
+	return e.registry.list()
+}
+
+// ServeRoutes register a debug endpoint which responds the registered routes
+func (e *EasyGin) ServeRoutes(relativePath string) {
+	e.Engine.GET(relativePath, func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, &OkData(e.RegisteredRoutes()).R)
+	})
+}
+
+// describeHandler fill the handler name and parameters of the route
+func (info *RouteInfo) describeHandler(fv reflect.Value, authenticated bool) {
+	ft := fv.Type()
+	info.Handler = handlerName(fv)
+	info.authenticated = authenticated
+	info.Params = make([]ParamInfo, 0, ft.NumIn())
+	info.params = make([]reflect.Type, 0, ft.NumIn())
+	for i := 0; i < ft.NumIn(); i++ {
+		info.params = append(info.params, ft.In(i))
+		info.Params = append(info.Params, describeParam(ft.In(i), authenticated))
+	}
+}
+
+func describeParam(in reflect.Type, authenticated bool) ParamInfo {
+	p := ParamInfo{Type: in.String()}
+	if in == ginCtxType {
+		p.Sources = []string{SourceContext}
+		return p
+	}
+	if authenticated && isPrincipalType(in) {
+		p.Sources = []string{SourcePrincipal}
+		return p
+	}
+
+	if in.Kind() == reflect.Pointer {
+		in = in.Elem()
+	}
+	if in.Kind() != reflect.Struct {
+		p.Sources = []string{SourceQuery}
+		return p
+	}
+
+	tags := structTagsOf(in)
+	p.Sources = []string{SourceQuery, SourceBody}
+	if tags.uri {
+		p.Sources = append(p.Sources, SourceURI)
+	}
+	if len(tags.headers) > 0 {
+		p.Sources = append(p.Sources, SourceHeader)
+	}
+	for i := 0; i < in.NumField(); i++ {
+		f := in.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		p.Fields = append(p.Fields, FieldInfo{
+			Name:     f.Name,
+			Type:     f.Type.String(),
+			JSON:     tagName(f.Tag.Get("json")),
+			Form:     tagName(f.Tag.Get("form")),
+			URI:      tagName(f.Tag.Get("uri")),
+			Header:   tagName(f.Tag.Get("header")),
+			Required: hasTagOption(f.Tag.Get("binding"), "required"),
+		})
+	}
+	return p
+}
+
+// structTags records the binding tags used by a struct
+type structTags struct {
+	uri     bool
+	headers []string
+}
+
+var structTagsCache sync.Map // reflect.Type --> structTags
+
+func structTagsOf(t reflect.Type) structTags {
+	if v, ok := structTagsCache.Load(t); ok {
+		return v.(structTags)
+	}
+
+	var tags structTags
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if tagName(f.Tag.Get("uri")) != "" {
+			tags.uri = true
+		}
+		if name := tagName(f.Tag.Get("header")); name != "" {
+			tags.headers = append(tags.headers, name)
+		}
+	}
+	structTagsCache.Store(t, tags)
+	return tags
+}
+
+// mapURIAndHeader set the fields with uri or header tags of ptr from path params and headers
+func mapURIAndHeader(ctx *gin.Context, tags structTags, ptr interface{}) error {
+	if tags.uri && len(ctx.Params) > 0 {
+		params := make(map[string][]string, len(ctx.Params))
+		for _, p := range ctx.Params {
+			params[p.Key] = []string{p.Value}
+		}
+		if err := binding.MapFormWithTag(ptr, params, "uri"); err != nil {
+			return err
+		}
+	}
+	if len(tags.headers) > 0 {
+		headers := make(map[string][]string, len(tags.headers))
+		for _, name := range tags.headers {
+			if vs := ctx.Request.Header.Values(name); len(vs) > 0 {
+				headers[name] = vs
+			}
+		}
+		if err := binding.MapFormWithTag(ptr, headers, "header"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagName return the name part of a tag, "-" is treated as no name
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func hasTagOption(tag, option string) bool {
+	for _, opt := range strings.Split(tag, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
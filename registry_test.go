@@ -0,0 +1,81 @@
+package easygin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetUserReq struct {
+	Id    int    `uri:"id" binding:"required"`
+	Token string `header:"X-Token"`
+	Email string `form:"email"`
+}
+
+type UpdateUserReq struct {
+	Id       int    `uri:"id"`
+	Username string `json:"username" binding:"required"`
+}
+
+func TestRegisteredRoutes(t *testing.T) {
+	server := New()
+	server.ServeRoutes("/debug/routes")
+	group := server.Group("/api")
+	group.GET("/user/:id", Returns[User](), func(ctx *gin.Context, req *GetUserReq) *Response {
+		return OkData(req)
+	})
+	server.POST("/user", func(ctx *gin.Context, user User) *Response {
+		return Ok()
+	})
+
+	routes := server.RegisteredRoutes()
+	if len(routes) != 2 {
+		t.Fatalf("expect 2 routes, got %d", len(routes))
+	}
+	r := routes[0]
+	if r.Method != http.MethodGet || r.Path != "/api/user/:id" || r.Response != "easygin.User" ||
+		!strings.HasSuffix(r.Handler, "TestRegisteredRoutes.func1") {
+		t.Errorf("unexpected route: %+v", r)
+	}
+	if len(r.Params) != 2 || r.Params[0].Sources[0] != SourceContext ||
+		strings.Join(r.Params[1].Sources, ",") != "query,body,uri,header" ||
+		r.Params[1].Fields[0].URI != "id" || !r.Params[1].Fields[0].Required {
+		t.Errorf("unexpected params: %+v", r.Params)
+	}
+
+	if w := serveRequest(server, http.MethodGet, "/debug/routes", nil); !strings.Contains(w.Body.String(), `"path":"/user"`) {
+		t.Errorf("unexpected debug routes: %s", w.Body.String())
+	}
+}
+
+func TestBindURIAndHeader(t *testing.T) {
+	server := New()
+	server.GET("/user/:id", func(ctx *gin.Context, req *GetUserReq) *Response {
+		return OkData(req)
+	})
+	server.PUT("/user/:id", func(ctx *gin.Context, req *UpdateUserReq) *Response {
+		return OkData(req)
+	})
+
+	tests := []struct {
+		name, method, path, body string
+		header                   []string
+		expect                   string
+	}{
+		{"uri and header", http.MethodGet, "/user/3?email=a@b.com", "", []string{"X-Token", "token"},
+			`{"data":{"Id":3,"Token":"token","Email":"a@b.com"},"code":0,"message":"success"}`},
+		// the uri field does not fail the validation of the required body field before the body is bound
+		{"uri and body", http.MethodPut, "/user/5", `{"username":"tom"}`, []string{"Content-Type", ContentTypeJson},
+			`{"data":{"Id":5,"username":"tom"},"code":0,"message":"success"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serveRequest(server, test.method, test.path, strings.NewReader(test.body), test.header...)
+			if w.Body.String() != test.expect {
+				t.Errorf("expect %s, got %s", test.expect, w.Body.String())
+			}
+		})
+	}
+}
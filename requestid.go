@@ -0,0 +1,144 @@
+package easygin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	requestIDKey       = "easygin/request_id"
+	requestIDInRespKey = "easygin/request_id_in_resp"
+
+	maxRequestIDLen = 128
+)
+
+type requestIDCtxKey struct{}
+
+// RequestIDConfig is used to configure the RequestID middleware
+type RequestIDConfig struct {
+	// Header is the header from which the request id is read and to which it is written,
+	// default is X-Request-ID
+	Header string
+	// Generator generates a new request id when the request does not carry a valid one,
+	// default generates 32 random hex characters
+	Generator func() string
+	// IncludeInResponse adds the request id to the response envelope as the request_id field
+	IncludeInResponse bool
+}
+
+// RequestID return a middleware which accepts the request id from the request header
+// or generates a new one, stores it in gin.Context and context.Context and echoes it in the response header
+func RequestID(configs ...RequestIDConfig) gin.HandlerFunc {
+	var cfg RequestIDConfig
+	if len(configs) > 0 {
+		cfg = configs[0]
+	}
+	if cfg.Header == "" {
+		cfg.Header = headerRequestID
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = newRequestID
+	}
+
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(cfg.Header)
+		if !validRequestID(id) {
+			id = cfg.Generator()
+		}
+
+		ctx.Set(requestIDKey, id)
+		if cfg.IncludeInResponse {
+			ctx.Set(requestIDInRespKey, true)
+		}
+		ctx.Request = ctx.Request.WithContext(ContextWithRequestID(ctx.Request.Context(), id))
+		ctx.Header(cfg.Header, id)
+
+		ctx.Next()
+	}
+}
+
+// GetRequestID return the request id stored by the RequestID middleware
+func GetRequestID(ctx *gin.Context) string {
+	return ctx.GetString(requestIDKey)
+}
+
+// ContextWithRequestID return a copy of ctx which carries the request id
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+// RequestIDFromContext return the request id carried by ctx, ctx can be *gin.Context
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if gc, ok := ctx.(*gin.Context); ok {
+		if id := GetRequestID(gc); id != "" {
+			return id
+		}
+		if gc.Request == nil {
+			return ""
+		}
+		ctx = gc.Request.Context()
+	}
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID only accepts ids which can be written to headers, logs and json without escaping
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDHandler adds the request id carried by the context to every log record
+type requestIDHandler struct {
+	slog.Handler
+}
+
+func withRequestID(l Logger) Logger {
+	sl, ok := l.(*slog.Logger)
+	if !ok {
+		return l
+	}
+	if _, ok = sl.Handler().(*requestIDHandler); ok {
+		return l
+	}
+	return slog.New(&requestIDHandler{sl.Handler()})
+}
+
+func (h *requestIDHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &requestIDHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *requestIDHandler) WithGroup(name string) slog.Handler {
+	return &requestIDHandler{h.Handler.WithGroup(name)}
+}
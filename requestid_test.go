@@ -0,0 +1,80 @@
+package easygin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	server := New()
+	server.SetLogger(slog.New(slog.NewJSONHandler(buf, nil)))
+	server.Use(RequestID(RequestIDConfig{IncludeInResponse: true}))
+	// the error is shared by the requests
+	respErr := NewFromError(errors.New("internal error"))
+	var (
+		mu       sync.Mutex
+		attached = make(map[string]string)
+	)
+	server.Intercept(func(ctx *gin.Context, req interface{}, resp *Response) *Response {
+		mu.Lock()
+		attached[GetRequestID(ctx)] = resp.R.RespError.Error()
+		mu.Unlock()
+		return resp
+	})
+	server.GET("/test", func(ctx *gin.Context) *Response {
+		server.Logger().Log(ctx.Request.Context(), slog.LevelInfo, "in handler")
+		return Fail(respErr)
+	})
+
+	t.Run("propagated", func(t *testing.T) {
+		w := serveRequest(server, http.MethodGet, "/test", nil, "X-Request-ID", "req-1")
+		if w.Header().Get("X-Request-ID") != "req-1" {
+			t.Errorf("request id not echoed: %v", w.Header())
+		}
+		if w.Body.String() != `{"data":null,"code":-1,"message":"internal error","request_id":"req-1"}` {
+			t.Errorf("unexpected body: %s", w.Body.String())
+		}
+		if !strings.Contains(buf.String(), `"request_id":"req-1"`) {
+			t.Errorf("request id not logged: %s", buf.String())
+		}
+		if attached["req-1"] != "[-1]internal error (request_id=req-1)" {
+			t.Errorf("request id not attached to error: %s", attached["req-1"])
+		}
+	})
+
+	t.Run("shared error", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				serveRequest(server, http.MethodGet, "/test", nil, "X-Request-ID", id)
+			}(fmt.Sprintf("req-%d", i))
+		}
+		wg.Wait()
+		for id, err := range attached {
+			if expect := "[-1]internal error (request_id=" + id + ")"; err != expect {
+				t.Errorf("expect %s, got %s", expect, err)
+			}
+		}
+		if respErr.Error() != "[-1]internal error" {
+			t.Errorf("expect the shared error not to be modified, got %s", respErr.Error())
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		w := serveRequest(server, http.MethodGet, "/test", nil, "X-Request-ID", "bad\"id")
+		if id := w.Header().Get("X-Request-ID"); len(id) != 32 {
+			t.Errorf("invalid request id should be replaced, got %q", id)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package easygin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const (
+	// listenFdsStart is the first file descriptor of the listeners passed to the process
+	listenFdsStart = 3
+	// envListenFds is the number of the listeners passed to the new process by the graceful restart
+	envListenFds = "EASYGIN_LISTEN_FDS"
+)
+
+var (
+	inheritOnce sync.Once
+	inheritMu   sync.Mutex
+	inherited   []net.Listener
+)
+
+// inheritedListeners load the listeners passed by systemd socket activation (LISTEN_FDS and LISTEN_PID)
+// or by the graceful restart, the environment variables are unset so that they are not passed to the children
+func inheritedListeners() []net.Listener {
+	inheritOnce.Do(func() {
+		n := 0
+		if fds := os.Getenv(envListenFds); fds != "" {
+			n, _ = strconv.Atoi(fds)
+		} else if pid, _ := strconv.Atoi(os.Getenv("LISTEN_PID")); pid == os.Getpid() {
+			n, _ = strconv.Atoi(os.Getenv("LISTEN_FDS"))
+		}
+		for _, env := range []string{envListenFds, "LISTEN_FDS", "LISTEN_PID", "LISTEN_FDNAMES"} {
+			_ = os.Unsetenv(env)
+		}
+
+		for fd := listenFdsStart; fd < listenFdsStart+n; fd++ {
+			syscall.CloseOnExec(fd)
+			f := os.NewFile(uintptr(fd), "listener-"+strconv.Itoa(fd))
+			// the listener dups the file descriptor, the file is closed whether it succeeds
+			l, err := net.FileListener(f)
+			_ = f.Close()
+			if err != nil {
+				defaultLogger().Log(context.Background(), slog.LevelWarn, "inherited file descriptor is not a listener", "fd", fd, "error", err)
+				continue
+			}
+			inherited = append(inherited, l)
+		}
+	})
+	return inherited
+}
+
+// takeInherited remove the inherited listener listening on the address and return it, nil if there is none
+func takeInherited(network, addr string) net.Listener {
+	inheritMu.Lock()
+	defer inheritMu.Unlock()
+	listeners := inheritedListeners()
+	for i, l := range listeners {
+		if sameAddr(l.Addr(), network, addr) {
+			inherited = append(listeners[:i:i], listeners[i+1:]...)
+			return l
+		}
+	}
+	return nil
+}
+
+// takeAllInherited remove all the inherited listeners and return them
+func takeAllInherited() []net.Listener {
+	inheritMu.Lock()
+	defer inheritMu.Unlock()
+	listeners := inheritedListeners()
+	inherited = nil
+	return listeners
+}
+
+// notifyRestart register e for the graceful restart of its SignalManager, c receives the pid of the new process
+// started on SIGHUP, stop stops the notification
+func (e *EasyGin) notifyRestart() (c <-chan int, stop func()) {
+	return e.SignalManager().notifyRestart(e)
+}
+
+// notifyRestart register e for the graceful restart, SIGHUP is handled until all the instances stop the notification,
+// so it does not terminate the process while the instances drain
+func (m *SignalManager) notifyRestart(e *EasyGin) (c <-chan int, stop func()) {
+	m.restartMu.Lock()
+	defer m.restartMu.Unlock()
+	if len(m.restarts) == 0 {
+		m.restarts = make(map[*EasyGin]*restartNotify)
+		m.removeRestart = m.Handle(m.restart, syscall.SIGHUP)
+	}
+	n := &restartNotify{c: make(chan int, 1)}
+	m.restarts[e] = n
+
+	return n.c, func() {
+		m.restartMu.Lock()
+		defer m.restartMu.Unlock()
+		if m.restarts[e] != n {
+			return
+		}
+		delete(m.restarts, e)
+		if len(m.restarts) == 0 {
+			m.removeRestart()
+		}
+	}
+}
+
+// restart start one new process with the listeners of all the instances which are not restarted yet,
+// then notify them, the instances keep serving if it fails
+func (m *SignalManager) restart(os.Signal) {
+	m.restartMu.Lock()
+	defer m.restartMu.Unlock()
+	var (
+		instances []*EasyGin
+		listeners []*serverListener
+	)
+	for e, n := range m.restarts {
+		if !n.restarted {
+			instances = append(instances, e)
+			listeners = append(listeners, e.listenerList()...)
+		}
+	}
+	if len(instances) == 0 {
+		return
+	}
+
+	pid, err := restartProcess(listeners)
+	for _, e := range instances {
+		if err != nil {
+			e.Logger().Log(context.Background(), slog.LevelError, "graceful restart failed", "error", err)
+			continue
+		}
+		n := m.restarts[e]
+		n.restarted = true
+		n.c <- pid
+	}
+}
+
+// restartProcess start the new process with the listening sockets, the connections are queued in the sockets
+// until the new process accepts them, so no connection is dropped while the old process drains
+func restartProcess(listeners []*serverListener) (int, error) {
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, l := range listeners {
+		fl, ok := l.raw.(interface{ File() (*os.File, error) })
+		if !ok {
+			return 0, fmt.Errorf("listener %s can not be passed to the new process", l.Addr())
+		}
+		f, err := fl.File()
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, f)
+	}
+
+	cmd := restartCommand()
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, envListenFds+"=") && !strings.HasPrefix(env, "LISTEN_") {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+	cmd.Env = append(cmd.Env, envListenFds+"="+strconv.Itoa(len(files)))
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	// the socket files are used by the new process, they are not removed when the listeners are closed
+	for _, l := range listeners {
+		if ul, ok := l.raw.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+	return pid, nil
+}
@@ -0,0 +1,149 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package easygin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestGracefulRestart(t *testing.T) {
+	if addr := os.Getenv("EASYGIN_TEST_RESTART_ADDR"); addr != "" {
+		// the new process started by the graceful restart, it exits after a request
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server := New()
+		server.SetSignalHandler(func() context.Context { return ctx })
+		server.GET("/pid", func() *Response {
+			defer cancel()
+			return OkData(os.Getpid())
+		})
+		if err := server.ListenAndServe(addr); !errors.Is(err, http.ErrServerClosed) {
+			t.Fatal(err)
+		}
+		return
+	}
+	if testing.Short() {
+		t.Skip("graceful restart starts a new process")
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EASYGIN_TEST_RESTART_ADDR", l.Addr().String())
+	defer func(command func() *exec.Cmd) {
+		restartCommand = command
+	}(restartCommand)
+	restartCommand = func() *exec.Cmd {
+		return exec.Command(os.Args[0], "-test.run=^TestGracefulRestart$")
+	}
+
+	b := newBlocker()
+	server := New()
+	server.EnableGracefulRestart()
+	server.SetSignalHandler(context.Background)
+	server.GET("/slow", func() *Response {
+		b.wait()
+		return OkData(os.Getpid())
+	})
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(l)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 10 * time.Second}
+	slow := make(chan string, 1)
+	go func() {
+		_, body := httpGet(client, "http://"+l.Addr().String()+"/slow")
+		slow <- body
+	}()
+
+	// the old process drains the in-flight request
+	b.drain(func() {
+		process, _ := os.FindProcess(os.Getpid())
+		if err := process.Signal(syscall.SIGHUP); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		// SIGHUP is still handled while draining, it does not terminate the process
+		_ = process.Signal(syscall.SIGHUP)
+		time.Sleep(50 * time.Millisecond)
+		select {
+		case err := <-serveErr:
+			t.Fatalf("expect the server to be drained, got %v", err)
+		default:
+		}
+	})
+	if body, expect := <-slow, fmt.Sprintf(`{"data":%d,"code":0,"message":"success"}`, os.Getpid()); body != expect {
+		t.Errorf("expect %s, got %s", expect, body)
+	}
+	if err = <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("expect ErrServerClosed, got %v", err)
+	}
+
+	// the new process accepts the connections of the socket
+	_, body := httpGet(client, "http://"+l.Addr().String()+"/pid")
+	var resp struct{ Data int }
+	if json.Unmarshal([]byte(body), &resp) != nil || resp.Data == 0 || resp.Data == os.Getpid() {
+		t.Errorf("expect the response of the new process, got %s", body)
+	}
+}
+
+func TestGracefulRestartSharedManager(t *testing.T) {
+	defer func(command func() *exec.Cmd) {
+		restartCommand = command
+	}(restartCommand)
+	var started atomic.Int32
+	restartCommand = func() *exec.Cmd {
+		started.Add(1)
+		return exec.Command("true")
+	}
+
+	// the instances sharing the manager are restarted by one new process with all their listeners
+	manager := NewSignalManager()
+	defer manager.Reset()
+	var servers []*EasyGin
+	serveErr := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		server := New()
+		server.SetSignalManager(manager)
+		server.SetSignalHandler(context.Background)
+		server.EnableGracefulRestart()
+		server.GET("/ping", func() *Response {
+			return Ok()
+		})
+		if err := server.Listen(ListenConfig{Addr: "127.0.0.1:0"}); err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			serveErr <- server.RunContext(context.Background())
+		}()
+		waitServing(t, server.Addrs()[0].String())
+		servers = append(servers, server)
+	}
+
+	process, _ := os.FindProcess(os.Getpid())
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	for range servers {
+		if err := <-serveErr; err != nil {
+			t.Errorf("expect a clean shutdown, got %v", err)
+		}
+	}
+	if n := started.Load(); n != 1 {
+		t.Errorf("expect one new process, got %d", n)
+	}
+}
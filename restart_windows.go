@@ -0,0 +1,20 @@
+//go:build windows
+// +build windows
+
+package easygin
+
+import (
+	"net"
+)
+
+func takeInherited(network, addr string) net.Listener {
+	return nil
+}
+
+func takeAllInherited() []net.Listener {
+	return nil
+}
+
+func (e *EasyGin) notifyRestart() (c <-chan int, stop func()) {
+	return nil, func() {}
+}
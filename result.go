@@ -17,14 +17,20 @@ type Response struct {
 type RespValue struct {
 	RespError
 	Data interface{} `json:"data"`
+	// RequestID is only output when the RequestID middleware is configured with IncludeInResponse
+	RequestID string `json:"request_id,omitempty"`
+	// Meta is the metadata of the envelope, it is output as "meta" if not empty, etc.: set by interceptors
+	Meta map[string]interface{} `json:"meta,omitempty"`
 }
 
 const (
-	jsonData    = `{"data":`
-	jsonCode    = `,"code":`
-	jsonMessage = `,"message":"`
-	jsonEnd     = `"}`
-	jsonLen     = len(`{"data":, "code":,"message":""}`)
+	jsonData      = `{"data":`
+	jsonCode      = `,"code":`
+	jsonMessage   = `,"message":"`
+	jsonRequestID = `","request_id":"`
+	jsonMeta      = `","meta":`
+	jsonEnd       = `"}`
+	jsonLen       = len(`{"data":, "code":,"message":""}`)
 )
 
 func (r *RespValue) MarshalJSON() ([]byte, error) {
@@ -33,17 +39,33 @@ func (r *RespValue) MarshalJSON() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	var meta []byte
+	if len(r.Meta) > 0 {
+		if meta, err = json.Marshal(r.Meta); err != nil {
+			return nil, err
+		}
+	}
 	num := strconv.Itoa(r.Code())
 
 	buffer := bytes.NewBuffer(nil)
-	buffer.Grow(jsonLen + len(bs) + len(num) + len(r.Message()))
+	buffer.Grow(jsonLen + len(bs) + len(num) + len(r.Message()) + len(jsonRequestID) + len(r.RequestID) + len(jsonMeta) + len(meta))
 	buffer.WriteString(jsonData)
 	buffer.Write(bs)
 	buffer.WriteString(jsonCode)
 	buffer.WriteString(num)
 	buffer.WriteString(jsonMessage)
 	buffer.WriteString(r.Message())
-	buffer.WriteString(jsonEnd)
+	if r.RequestID != "" {
+		buffer.WriteString(jsonRequestID)
+		buffer.WriteString(r.RequestID)
+	}
+	if meta != nil {
+		buffer.WriteString(jsonMeta)
+		buffer.Write(meta)
+		buffer.WriteByte('}')
+	} else {
+		buffer.WriteString(jsonEnd)
+	}
 
 	return buffer.Bytes(), nil
 }
@@ -59,10 +81,21 @@ func NewResponse(status int, data interface{}, respErr RespError) *Response {
 	res.Status = status
 	res.R.RespError = respErr
 	res.R.Data = data
+	res.R.RequestID = ""
+	res.R.Meta = nil
 
 	return res
 }
 
+// SetMeta set the metadata of the envelope
+func (r *Response) SetMeta(key string, value interface{}) *Response {
+	if r.R.Meta == nil {
+		r.R.Meta = make(map[string]interface{})
+	}
+	r.R.Meta[key] = value
+	return r
+}
+
 func Ok() *Response {
 	return NewResponse(http.StatusOK, nil, RespSuccess)
 }
@@ -87,6 +120,11 @@ func FailData(respErr RespError, data interface{}) *Response {
 	return NewResponse(http.StatusOK, data, respErr)
 }
 
+// FailStatus return the response of respErr with the http status, etc.: FailStatus(http.StatusNotFound, RespNotFound)
+func FailStatus(status int, respErr RespError) *Response {
+	return NewResponse(status, nil, respErr)
+}
+
 func Error(status int) *Response {
 	return NewResponse(status, nil, nil)
 }
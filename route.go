@@ -0,0 +1,84 @@
+package easygin
+
+import (
+	"path"
+	"reflect"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// route describes a route registered through EasyGin or RouterGroup
+type route struct {
+	// method is empty if the route matches several methods, the method of the request is used
+	method string
+	// path is the full path template of the route, etc.: /api/user/:id
+	path   string
+	engine *EasyGin
+	info   *RouteInfo
+	// middlewares and interceptors are those of the group when the route is registered
+	middlewares  []Middleware
+	interceptors []Interceptor
+	timeout      *TimeoutConfig
+	guards       []guard
+}
+
+func newRoute(engine *EasyGin, method, path string) *route {
+	return &route{
+		method: method,
+		path:   path,
+		engine: engine,
+		info:   &RouteInfo{Method: method, Path: path},
+	}
+}
+
+// applyOptions apply the RouteOption among handlers and return the rest handlers
+func (rt *route) applyOptions(handlers []Handler) []Handler {
+	rest := make([]Handler, 0, len(handlers))
+	for _, h := range handlers {
+		if opt, ok := h.(RouteOption); ok {
+			opt.applyRoute(rt)
+			continue
+		}
+		rest = append(rest, h)
+	}
+	return rest
+}
+
+// requestMethod return the method of the route, or the method of the request if the route has no method
+func (rt *route) requestMethod(ctx *gin.Context) string {
+	if rt.method != "" {
+		return rt.method
+	}
+	return ctx.Request.Method
+}
+
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+
+	finalPath := path.Join(absolutePath, relativePath)
+	if relativePath[len(relativePath)-1] == '/' && finalPath[len(finalPath)-1] != '/' {
+		return finalPath + "/"
+	}
+	return finalPath
+}
+
+func handlerName(fv reflect.Value) string {
+	if f := runtime.FuncForPC(fv.Pointer()); f != nil {
+		return f.Name()
+	}
+	return fv.Type().String()
+}
+
+// ginHandlers convert the handlers of the route, the handlers creating the server span
+// and collecting metrics are prepended, they do nothing if tracing or metrics is not enabled
+func (rt *route) ginHandlers(handlers []Handler) []gin.HandlerFunc {
+	hs := append([]gin.HandlerFunc{rt.traceRoute, rt.measureRoute}, newHandlers(rt, handlers)...)
+	// the last handler is the one which handles the request, the others act as middlewares
+	if n := len(handlers); n > 0 {
+		rt.info.describeHandler(reflect.ValueOf(handlers[n-1]), authenticated(rt.guards))
+	}
+	return hs
+}
@@ -6,46 +6,32 @@ package easygin
 import (
 	"context"
 	"os"
-	"os/signal"
 	"syscall"
 )
 
-var onlyOneSignalHandler = make(chan struct{})
+var terminationSignals = []os.Signal{syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT}
 
+// SetupSignalHandler configure the default handlers of DefaultSignalManager, see SignalManager.Default
 func SetupSignalHandler() context.Context {
-	close(onlyOneSignalHandler) // panics when called twice
-
-	ctx, cancel := context.WithCancel(context.Background())
-
-	c := make(chan os.Signal, 2)
-	signal.Notify(c, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT, syscall.SIGUSR1, syscall.SIGUSR2)
+	return defaultSignalManager.Default(defaultLogger())
+}
 
+// handlePlatformSignals toggle profiling on SIGUSR1 and dump the goroutines on SIGUSR2
+func (m *SignalManager) handlePlatformSignals(logger Logger) {
 	var stopper Stopper
-	go func() {
-		for i := 0; i < 2; {
-			select {
-			case sig := <-c:
-				switch sig {
-				case syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGINT:
-					if i == 0 {
-						cancel()
-					} else {
-						os.Exit(0)
-					}
-					i++
-				case syscall.SIGUSR1:
-					if stopper == nil {
-						stopper = StartProfile()
-					} else {
-						stopper.Stop()
-						stopper = nil
-					}
-				case syscall.SIGUSR2:
-					dumpGoroutines()
-				}
-			}
+	m.Handle(func(os.Signal) {
+		// the profile may have stopped itself by the limits of the config
+		if s, ok := stopper.(interface{ Stopped() bool }); ok && s.Stopped() {
+			stopper = nil
 		}
-	}()
-
-	return ctx
+		if stopper == nil {
+			stopper = startProfile(logger, m.getProfileConfig())
+		} else {
+			stopper.Stop()
+			stopper = nil
+		}
+	}, syscall.SIGUSR1)
+	m.Handle(func(os.Signal) {
+		dumpGoroutines(logger)
+	}, syscall.SIGUSR2)
 }
@@ -0,0 +1,162 @@
+package easygin
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// SignalManager receives the signals and calls the handlers registered for them, it can be shared by several EasyGin
+type SignalManager struct {
+	mu       sync.Mutex
+	handlers map[os.Signal][]*signalHandler
+	c        chan os.Signal
+	done     chan struct{}
+	// shutdownCtx is the context returned by Default, it is canceled by the first termination signal
+	shutdownCtx context.Context
+	terminated  int
+	// profileConfig is the config of the profiling toggled by SIGUSR1
+	profileConfig ProfileConfig
+
+	restartMu sync.Mutex
+	// restarts is the instances of the graceful restart, one new process is started with all their listeners
+	restarts      map[*EasyGin]*restartNotify
+	removeRestart func()
+}
+
+// restartNotify notify an instance of the graceful restart with the pid of the new process
+type restartNotify struct {
+	c         chan int
+	restarted bool
+}
+
+type signalHandler struct {
+	handle func(sig os.Signal)
+}
+
+var defaultSignalManager = NewSignalManager()
+
+// DefaultSignalManager return the SignalManager used by the EasyGin without SetSignalManager
+func DefaultSignalManager() *SignalManager {
+	return defaultSignalManager
+}
+
+func NewSignalManager() *SignalManager {
+	return &SignalManager{handlers: make(map[os.Signal][]*signalHandler)}
+}
+
+// Handle register handler for the signals, the handlers are called in order in the goroutine of the manager.
+// remove removes the handler, the signals without handlers are reset to their default behavior
+func (m *SignalManager) Handle(handler func(sig os.Signal), sigs ...os.Signal) (remove func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.c == nil {
+		m.c, m.done = make(chan os.Signal, 4), make(chan struct{})
+		go m.loop(m.c, m.done)
+	}
+
+	h := &signalHandler{handle: handler}
+	for _, sig := range sigs {
+		m.handlers[sig] = append(m.handlers[sig], h)
+	}
+	signal.Notify(m.c, sigs...)
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		var stopped []os.Signal
+		for _, sig := range sigs {
+			handlers := m.handlers[sig]
+			for i := range handlers {
+				if handlers[i] == h {
+					m.handlers[sig] = append(handlers[:i:i], handlers[i+1:]...)
+					break
+				}
+			}
+			if len(m.handlers[sig]) == 0 {
+				delete(m.handlers, sig)
+				stopped = append(stopped, sig)
+			}
+		}
+		// only the signals without handlers are reset, the others are received without a gap
+		if len(stopped) > 0 && m.c != nil {
+			signal.Reset(stopped...)
+		}
+	}
+}
+
+// Reset stop receiving the signals and remove all the handlers, the manager can be used again after it, etc.: in tests
+func (m *SignalManager) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.c != nil {
+		signal.Stop(m.c)
+		close(m.done)
+		m.c, m.done = nil, nil
+	}
+	m.handlers = make(map[os.Signal][]*signalHandler)
+	m.shutdownCtx = nil
+	m.terminated = 0
+}
+
+func (m *SignalManager) loop(c chan os.Signal, done chan struct{}) {
+	for {
+		select {
+		case sig := <-c:
+			m.mu.Lock()
+			handlers := append([]*signalHandler(nil), m.handlers[sig]...)
+			m.mu.Unlock()
+			for _, h := range handlers {
+				h.handle(sig)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// Default configure the default handlers and return the context canceled by the first termination signal
+// (SIGTERM, SIGINT and SIGQUIT), the process exits on the second one. SIGUSR1 toggles profiling and
+// SIGUSR2 dumps the goroutines on linux and darwin. it is configured once until Reset, the same context is
+// returned by the later calls, so the servers sharing the manager shut down together
+func (m *SignalManager) Default(logger Logger) context.Context {
+	m.mu.Lock()
+	if m.shutdownCtx != nil {
+		defer m.mu.Unlock()
+		return m.shutdownCtx
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.shutdownCtx = ctx
+	m.mu.Unlock()
+
+	m.Handle(func(sig os.Signal) {
+		m.mu.Lock()
+		m.terminated++
+		terminated := m.terminated
+		m.mu.Unlock()
+		if terminated == 1 {
+			logger.Log(context.Background(), slog.LevelInfo, "received termination signal", "signal", sig)
+			cancel()
+			return
+		}
+		os.Exit(0)
+	}, terminationSignals...)
+	m.handlePlatformSignals(logger)
+	return ctx
+}
+
+// SetSignalManager set the SignalManager of the default signal handling and the graceful restart,
+// default is DefaultSignalManager
+func (e *EasyGin) SetSignalManager(m *SignalManager) {
+	e.signals = m
+}
+
+// SignalManager return the SignalManager of EasyGin
+func (e *EasyGin) SignalManager() *SignalManager {
+	if e.signals == nil {
+		return defaultSignalManager
+	}
+	return e.signals
+}
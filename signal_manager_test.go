@@ -0,0 +1,90 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package easygin
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSignalManager(t *testing.T) {
+	manager := NewSignalManager()
+	defer manager.Reset()
+	process, _ := os.FindProcess(os.Getpid())
+
+	t.Run("handlers", func(t *testing.T) {
+		// the handlers of a signal are called in order until removed
+		received := make(chan string, 2)
+		remove := manager.Handle(func(sig os.Signal) {
+			received <- "first " + sig.String()
+		}, syscall.SIGHUP)
+		defer manager.Handle(func(sig os.Signal) {
+			received <- "second " + sig.String()
+		}, syscall.SIGHUP)()
+		_ = process.Signal(syscall.SIGHUP)
+		if first, second := <-received, <-received; first != "first hangup" || second != "second hangup" {
+			t.Errorf("unexpected handlers %s, %s", first, second)
+		}
+		remove()
+		_ = process.Signal(syscall.SIGHUP)
+		if second := <-received; second != "second hangup" || len(received) != 0 {
+			t.Errorf("expect the removed handler not to be called, got %s", second)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		// removing the handlers of a signal does not stop receiving the others
+		received := make(chan os.Signal, 1)
+		defer manager.Handle(func(sig os.Signal) {
+			received <- sig
+		}, syscall.SIGWINCH)()
+		manager.Handle(func(os.Signal) {}, syscall.SIGUSR2)()
+		_ = process.Signal(syscall.SIGWINCH)
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Error("expect the signal with a handler to be received")
+		}
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		// the servers sharing the manager shut down together on the termination signal
+		runErr := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			server := New()
+			server.SetSignalManager(manager)
+			server.GET("/ping", func() *Response {
+				return Ok()
+			})
+			if err := server.Listen(ListenConfig{Addr: "127.0.0.1:0"}); err != nil {
+				t.Fatal(err)
+			}
+			go func() {
+				runErr <- server.RunContext(context.Background())
+			}()
+			waitServing(t, server.Addrs()[0].String())
+		}
+		_ = process.Signal(syscall.SIGTERM)
+		for i := 0; i < 2; i++ {
+			if err := <-runErr; err != nil {
+				t.Errorf("expect a clean shutdown, got %v", err)
+			}
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		// the default handlers are configured again after Reset
+		ctx := manager.Default(defaultLogger())
+		if ctx.Err() == nil || manager.Default(defaultLogger()) != ctx {
+			t.Error("expect the same canceled context before Reset")
+		}
+		manager.Reset()
+		if manager.Default(defaultLogger()).Err() != nil {
+			t.Error("expect a new context after Reset")
+		}
+	})
+}
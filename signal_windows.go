@@ -6,24 +6,14 @@ package easygin
 import (
 	"context"
 	"os"
-	"os/signal"
 	"syscall"
 )
 
-var onlyOneSignalHandler = make(chan struct{})
+var terminationSignals = []os.Signal{syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT}
 
+// SetupSignalHandler configure the default handlers of DefaultSignalManager, see SignalManager.Default
 func SetupSignalHandler() context.Context {
-	close(onlyOneSignalHandler)
-	c := make(chan os.Signal, 2)
-	ctx, cancle := context.WithCancel(context.Background())
-	signal.Notify(c, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT)
-
-	go func() {
-		<-c
-		cancle()
-		<-c
-		os.Exit(0)
-	}()
-
-	return ctx
+	return defaultSignalManager.Default(defaultLogger())
 }
+
+func (m *SignalManager) handlePlatformSignals(_ Logger) {}
@@ -0,0 +1,196 @@
+package easygin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const TimeoutCode = 504
+
+var RespTimeout = RespError(&RespErrorImpl{
+	Codee:    TimeoutCode,
+	Messagee: "request timeout",
+})
+
+// TimeoutConfig set a deadline on the context of the request passed to the handler,
+// the handler should return when ctx.Request.Context() is done.
+// on expiry, the Error is written with the Status, the result returned by the handler later is discarded
+type TimeoutConfig struct {
+	Timeout time.Duration
+	// Status is the http status of the timeout response, default is 504
+	Status int
+	// Error is the RespError of the timeout response, default is RespTimeout
+	Error RespError
+}
+
+// Timeout set the timeout of the route, it overrides the timeout of the group
+func Timeout(config TimeoutConfig) RouteOption {
+	return routeOptionFunc(func(rt *route) {
+		rt.timeout = config.withDefaults()
+	})
+}
+
+// SetTimeout set the timeout of the routes registered in the group after it,
+// it is inherited by the groups created after it
+func (r *RouterGroup) SetTimeout(config TimeoutConfig) {
+	r.timeout = config.withDefaults()
+}
+
+// SetTimeout set the timeout of the routes registered after it, see RouterGroup.SetTimeout
+func (e *EasyGin) SetTimeout(config TimeoutConfig) {
+	e.root.SetTimeout(config)
+}
+
+func (c TimeoutConfig) withDefaults() *TimeoutConfig {
+	if c.Timeout <= 0 {
+		return nil
+	}
+	if c.Status == 0 {
+		c.Status = http.StatusGatewayTimeout
+	}
+	if c.Error == nil {
+		c.Error = RespTimeout
+	}
+	return &c
+}
+
+// run call handle in a new goroutine with the deadline, it returns false if the deadline is exceeded
+// and the timeout response has been written. handle writes to a buffer instead of the response,
+// the buffer is copied to the response if handle returns in time. the request goroutine waits for handle
+// to return in any case, so the gin.Context is not reused while handle is using it
+func (c *TimeoutConfig) run(ctx *gin.Context, handle func() *Response) (*Response, bool) {
+	reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), c.Timeout)
+	defer cancel()
+	ctx.Request = ctx.Request.WithContext(reqCtx)
+
+	w := ctx.Writer
+	tw := &timeoutWriter{ResponseWriter: w, header: make(http.Header)}
+	ctx.Writer = tw
+	defer func() {
+		ctx.Writer = w
+	}()
+
+	done := make(chan *Response, 1)
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+			}
+		}()
+		done <- handle()
+	}()
+
+	select {
+	case result := <-done:
+		tw.copyTo(w)
+		return result, true
+	case p := <-panicked:
+		panic(p)
+	case <-reqCtx.Done():
+	}
+
+	tw.timeout()
+	resp := FailStatus(c.Status, c.Error)
+	if id := GetRequestID(ctx); id != "" {
+		if ctx.GetBool(requestIDInRespKey) {
+			resp.R.RequestID = id
+		}
+	}
+	ctx.Set(respCodeKey, c.Error.Code())
+	writeJSON(w, resp)
+	w.Flush()
+
+	// discard the late result
+	select {
+	case <-done:
+	case <-panicked:
+	}
+	return nil, false
+}
+
+// timeoutWriter buffers the response written by the handler, the writes are discarded after timeout
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	return tw.body.Write(b)
+}
+
+func (tw *timeoutWriter) WriteString(s string) (int, error) {
+	return tw.Write([]byte(s))
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.timedOut {
+		tw.status = code
+	}
+}
+
+func (tw *timeoutWriter) WriteHeaderNow() {}
+
+func (tw *timeoutWriter) Status() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.status == 0 {
+		return http.StatusOK
+	}
+	return tw.status
+}
+
+func (tw *timeoutWriter) Written() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.status != 0 || tw.body.Len() > 0
+}
+
+func (tw *timeoutWriter) Size() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.body.Len() == 0 {
+		return -1
+	}
+	return tw.body.Len()
+}
+
+func (tw *timeoutWriter) timeout() {
+	tw.mu.Lock()
+	tw.timedOut = true
+	tw.mu.Unlock()
+}
+
+func (tw *timeoutWriter) copyTo(w gin.ResponseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	for k, v := range tw.header {
+		w.Header()[k] = v
+	}
+	if tw.status != 0 {
+		w.WriteHeader(tw.status)
+	}
+	if tw.body.Len() > 0 {
+		_, _ = w.Write(tw.body.Bytes())
+	}
+}
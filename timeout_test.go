@@ -0,0 +1,68 @@
+package easygin
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeout(t *testing.T) {
+	server := New()
+	group := server.Group("/api")
+	group.SetTimeout(TimeoutConfig{Timeout: 20 * time.Millisecond})
+	late := make(chan bool, 1)
+	group.GET("/slow", func(ctx *gin.Context) *Response {
+		<-ctx.Request.Context().Done()
+		ctx.Header("X-Late", "1")
+		late <- true
+		return OkData("late")
+	})
+	group.GET("/fast", func(ctx *gin.Context) *Response {
+		ctx.Header("X-Fast", "1")
+		return OkData("fast")
+	})
+	group.GET("/custom", Timeout(TimeoutConfig{Timeout: time.Millisecond, Status: http.StatusServiceUnavailable, Error: NewError(9, "busy")}),
+		func(ctx *gin.Context) *Response {
+			time.Sleep(10 * time.Millisecond)
+			return Ok()
+		})
+	group.GET("/panic", func() *Response {
+		panic("boom")
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+		header string
+	}{
+		{"/api/slow", 504, `{"data":null,"code":504,"message":"request timeout"}`, ""},
+		{"/api/fast", 200, `{"data":"fast","code":0,"message":"success"}`, "X-Fast"},
+		{"/api/custom", 503, `{"data":null,"code":9,"message":"busy"}`, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			w := serveRequest(server, http.MethodGet, test.path, nil)
+			if w.Code != test.status || w.Body.String() != test.body || (test.header != "" && w.Header().Get(test.header) != "1") {
+				t.Errorf("expect %d %s, got %d %s %v", test.status, test.body, w.Code, w.Body.String(), w.Header())
+			}
+			if w.Header().Get("X-Late") != "" {
+				t.Error("the header of the late handler is written")
+			}
+		})
+	}
+	if !<-late {
+		t.Error("the slow handler is not finished")
+	}
+
+	t.Run("panic", func(t *testing.T) {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("expect the panic of the handler, got %v", p)
+			}
+		}()
+		serveRequest(server, http.MethodGet, "/api/panic", nil)
+	})
+}
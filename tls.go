@@ -0,0 +1,200 @@
+package easygin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultCertReloadInterval = 10 * time.Second
+
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is the PEM file of the CAs verifying the client certificates, mutual TLS is enabled if it is set
+	ClientCAFile string
+	// ClientAuth is the policy of the client certificates,
+	// default is tls.RequireAndVerifyClientCert if ClientCAFile is set
+	ClientAuth tls.ClientAuthType
+	// ReloadInterval is the minimum interval of checking whether the certificate files are changed,
+	// the changed certificate is used by the new connections without restart. default is 10s, negative disables it
+	ReloadInterval time.Duration
+	// MinVersion is the minimum TLS version, default is TLS 1.2
+	MinVersion uint16
+}
+
+// ListenAndServeTLS listen on addr and serve HTTPS with the listeners opened by Listen, see Serve
+func (e *EasyGin) ListenAndServeTLS(addr string, config TLSConfig) error {
+	if e.Server == nil {
+		e.Server = &http.Server{
+			Addr:    addr,
+			Handler: e,
+		}
+	}
+	if e.Server.Addr == "" {
+		e.Server.Addr = ":https"
+	}
+
+	if err := e.Listen(ListenConfig{Addr: e.Server.Addr, TLS: &config}); err != nil {
+		return err
+	}
+	return e.serve()
+}
+
+// newTLSConfig create the tls.Config of the server, the certificate is reloaded when its files are changed
+func (e *EasyGin) newTLSConfig(config TLSConfig) (*tls.Config, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("tls: the certificate and key files are required")
+	}
+	if config.ReloadInterval == 0 {
+		config.ReloadInterval = defaultCertReloadInterval
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	reloader := &certReloader{config: config, logger: e.Logger()}
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:     config.MinVersion,
+		GetCertificate: reloader.getCertificate,
+	}
+
+	if config.ClientCAFile != "" {
+		pem, err := os.ReadFile(config.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: read client ca: %w", err)
+		}
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificate in client ca %s", config.ClientCAFile)
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if config.ClientAuth != tls.NoClientCert {
+		tlsConfig.ClientAuth = config.ClientAuth
+	}
+	return tlsConfig, nil
+}
+
+// certReloader keeps the certificate of the server, it checks the modification time of the files
+// at most once in ReloadInterval during the handshakes, the old certificate is kept if the reloading fails
+type certReloader struct {
+	config TLSConfig
+	logger Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+func (r *certReloader) load() error {
+	modTime, err := r.filesModTime()
+	if err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("tls: load certificate: %w", err)
+	}
+	r.cert, r.modTime, r.lastCheck = &cert, modTime, time.Now()
+	return nil
+}
+
+// filesModTime return the latest modification time of the certificate and key files
+func (r *certReloader) filesModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.config.CertFile, r.config.KeyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.config.ReloadInterval < 0 || time.Since(r.lastCheck) < r.config.ReloadInterval {
+		return r.cert, nil
+	}
+
+	r.lastCheck = time.Now()
+	if modTime, err := r.filesModTime(); err == nil && modTime.Equal(r.modTime) {
+		return r.cert, nil
+	}
+	if err := r.load(); err != nil {
+		r.logger.Log(context.Background(), slog.LevelError, "reload certificate failed", "cert", r.config.CertFile, "error", err)
+		return r.cert, nil
+	}
+	r.logger.Log(context.Background(), slog.LevelInfo, "certificate reloaded", "cert", r.config.CertFile)
+	return r.cert, nil
+}
+
+// ClientCertificate return the verified certificate of the client, nil if the client is not verified by mutual TLS
+func ClientCertificate(ctx *gin.Context) *x509.Certificate {
+	state := ctx.Request.TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+// ClientCertPrincipal is the principal of MutualTLS, it is the identity in the client certificate
+type ClientCertPrincipal struct {
+	CommonName     string   `json:"common_name"`
+	Organization   []string `json:"organization,omitempty"`
+	DNSNames       []string `json:"dns_names,omitempty"`
+	EmailAddresses []string `json:"email_addresses,omitempty"`
+	URIs           []string `json:"uris,omitempty"`
+	SerialNumber   string   `json:"serial_number"`
+
+	Certificate *x509.Certificate `json:"-"`
+}
+
+type mutualTLSAuthenticator struct{}
+
+// MutualTLS authenticate the requests by the client certificates verified by the ClientCAFile of TLSConfig,
+// the principal is *ClientCertPrincipal
+func MutualTLS() Authenticator {
+	return mutualTLSAuthenticator{}
+}
+
+func (mutualTLSAuthenticator) Authenticate(ctx *gin.Context) (interface{}, error) {
+	cert := ClientCertificate(ctx)
+	if cert == nil {
+		return nil, ErrNoCredentials
+	}
+	principal := &ClientCertPrincipal{
+		CommonName:     cert.Subject.CommonName,
+		Organization:   cert.Subject.Organization,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		SerialNumber:   cert.SerialNumber.String(),
+		Certificate:    cert,
+	}
+	for _, uri := range cert.URIs {
+		principal.URIs = append(principal.URIs, uri.String())
+	}
+	return principal, nil
+}
+
+func init() {
+	RegisterPrincipal[ClientCertPrincipal]()
+}
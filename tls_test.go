@@ -0,0 +1,133 @@
+package easygin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestCert create a certificate signed by parent, it is self-signed if parent is nil,
+// the PEM files of the certificate and key are written to dir
+func newTestCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name, Organization: []string{"easygin"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(crand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	if err = os.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return cert, key
+}
+
+func TestTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newTestCert(t, dir, "ca", nil, nil)
+	newTestCert(t, dir, "server", ca, caKey)
+	newTestCert(t, dir, "client", ca, caKey)
+
+	server := New()
+	server.GET("/whoami", Auth(MutualTLS()), func(ctx *gin.Context, client *ClientCertPrincipal) *Response {
+		if ClientCertificate(ctx).Subject.CommonName != client.CommonName {
+			t.Error("expect the client certificate")
+		}
+		return OkData(client.CommonName)
+	})
+	tlsConfig, err := server.newTLSConfig(TLSConfig{
+		CertFile:       filepath.Join(dir, "server.crt"),
+		KeyFile:        filepath.Join(dir, "server.key"),
+		ClientCAFile:   filepath.Join(dir, "ca.crt"),
+		ClientAuth:     tls.VerifyClientCertIfGiven,
+		ReloadInterval: time.Nanosecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewUnstartedServer(server)
+	ts.TLS = tlsConfig
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	clientCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(certs ...tls.Certificate) (*http.Response, string) {
+		client := &http.Client{Transport: &http.Transport{
+			DisableKeepAlives: true,
+			// the server name is sent to select the certificate of the config instead of the one of httptest
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs, ServerName: "localhost"},
+		}}
+		resp, err := client.Get(ts.URL + "/whoami")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	t.Run("client certificate", func(t *testing.T) {
+		resp, body := request(clientCert)
+		if body != `{"data":"client","code":0,"message":"success"}` || resp.TLS.PeerCertificates[0].Subject.CommonName != "server" {
+			t.Errorf("unexpected response %d %s", resp.StatusCode, body)
+		}
+		if resp, body = request(); resp.StatusCode != 401 {
+			t.Errorf("expect 401 without the client certificate, got %d %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		// the new certificate is used by the new connections
+		newTestCert(t, dir, "renewed", ca, caKey)
+		for _, ext := range []string{".crt", ".key"} {
+			data, _ := os.ReadFile(filepath.Join(dir, "renewed"+ext))
+			file := filepath.Join(dir, "server"+ext)
+			_ = os.WriteFile(file, data, 0o600)
+			_ = os.Chtimes(file, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+		}
+		if resp, _ := request(clientCert); resp.TLS.PeerCertificates[0].Subject.CommonName != "renewed" {
+			t.Errorf("expect the reloaded certificate, got %s", resp.TLS.PeerCertificates[0].Subject.CommonName)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package easygin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/mangohow/easygin"
+
+	spanBind    = "easygin.bind"
+	spanHandler = "easygin.handler"
+
+	attrHandler  = "easygin.handler"
+	attrRespCode = "easygin.code"
+)
+
+// SetTracerProvider enable tracing, a server span is created for every route registered through
+// EasyGin and RouterGroup, with sub spans for parameter binding and handler invocation.
+// pass nil to disable tracing
+func (e *EasyGin) SetTracerProvider(tp trace.TracerProvider) {
+	if tp == nil {
+		e.tracer = nil
+		return
+	}
+	e.tracer = tp.Tracer(tracerName)
+}
+
+// SetPropagator set the propagator which extracts the parent span from request headers,
+// default is the W3C trace context propagator
+func (e *EasyGin) SetPropagator(p propagation.TextMapPropagator) {
+	e.propagator = p
+}
+
+func (rt *route) tracer() trace.Tracer {
+	if rt == nil || rt.engine == nil {
+		return nil
+	}
+	return rt.engine.tracer
+}
+
+// traceRoute is the first handler of every route, it creates the server span of the route
+// and stores it in the context of the request
+func (rt *route) traceRoute(ctx *gin.Context) {
+	tracer := rt.tracer()
+	if tracer == nil {
+		return
+	}
+
+	propagator := rt.engine.propagator
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+	parent := propagator.Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
+	method := rt.requestMethod(ctx)
+	spanCtx, span := tracer.Start(parent, method+" "+rt.path,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(method), semconv.HTTPRoute(rt.path)))
+	defer span.End()
+
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if code, ok := ctx.Get(respCodeKey); ok {
+		span.SetAttributes(attribute.Int(attrRespCode, code.(int)))
+	}
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
+
+// callTraced call the handler in a sub span, the span is available in ctx.Request.Context() of the handler
+func callTraced(tracer trace.Tracer, ctx *gin.Context, name string, call func() *Response) *Response {
+	req := ctx.Request
+	spanCtx, span := tracer.Start(req.Context(), spanHandler, trace.WithAttributes(attribute.String(attrHandler, name)))
+	defer func() {
+		ctx.Request = req
+		span.End()
+	}()
+
+	ctx.Request = req.WithContext(spanCtx)
+	return call()
+}
+
+func endSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,45 @@
+package easygin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	server := New()
+	server.SetTracerProvider(tp)
+	server.GET("/user/:id", func(ctx *gin.Context, user *User) *Response {
+		if !oteltrace.SpanFromContext(ctx.Request.Context()).SpanContext().IsValid() {
+			t.Error("span is not propagated to handler")
+		}
+		return Fail(NewError(2, "fail"))
+	})
+
+	serveRequest(server, http.MethodGet, "/user/1?id=1", nil, "traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("expect 3 spans, got %d", len(spans))
+	}
+	root := spans[2]
+	if root.Name != "GET /user/:id" || root.SpanContext.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("unexpected root span: %s %s", root.Name, root.SpanContext.TraceID())
+	}
+	attrs := map[string]interface{}{}
+	for _, kv := range root.Attributes {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	if attrs["http.route"] != "/user/:id" || attrs["http.response.status_code"] != int64(200) || attrs["easygin.code"] != int64(2) {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+	if spans[0].Name != spanBind || spans[1].Name != spanHandler || spans[1].Parent.SpanID() != root.SpanContext.SpanID() {
+		t.Errorf("unexpected sub spans: %s %s", spans[0].Name, spans[1].Name)
+	}
+}
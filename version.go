@@ -0,0 +1,277 @@
+package easygin
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VersionStrategy decides where the version of a request is read from
+type VersionStrategy int
+
+const (
+	// VersionByPath select the version by the path prefix, etc.: /api/v2/users
+	VersionByPath VersionStrategy = iota
+	// VersionByHeader select the version by a custom header, etc.: X-API-Version: 2
+	VersionByHeader
+	// VersionByAccept select the version by the parameter of the Accept media type, etc.: Accept: application/json; version=2
+	VersionByAccept
+)
+
+const (
+	defaultVersionHeader = "X-API-Version"
+	defaultVersionParam  = "version"
+
+	UnsupportedVersionCode = 400
+)
+
+var RespUnsupportedVersion = RespError(&RespErrorImpl{
+	Codee:    UnsupportedVersionCode,
+	Messagee: "unsupported api version",
+})
+
+type VersioningConfig struct {
+	Strategy VersionStrategy
+	// Header is the header of VersionByHeader, default is X-API-Version
+	Header string
+	// Param is the media type parameter of VersionByAccept, default is version
+	Param string
+	// Default is the version of the requests without version, default is the latest version
+	Default string
+}
+
+// Versions serves the versions of the routes under the path of a group.
+// the routes of a version are registered under the path of the version, etc.: /api/v1/users,
+// with VersionByHeader or VersionByAccept, requests without the version in path are rewritten
+// to the path of the selected version before routing, so all the routes under the path must be versioned.
+//
+// a route registered in a version but not in a newer one falls through, the newer version serves it
+// with the handlers of the newest earlier version which has it. the fallthrough routes are registered
+// by EasyGin.Prepare before serving, so the routes of the versions must be registered before that
+type Versions struct {
+	group  *RouterGroup
+	config VersioningConfig
+	// versions in the order they are created, from the oldest to the latest
+	versions []*VersionGroup
+}
+
+// VersionGroup is the RouterGroup of a version
+type VersionGroup struct {
+	*RouterGroup
+	name        string
+	deprecation *Deprecation
+	// routes registered in the version, they are used for the fallthrough of the newer versions
+	routes []versionedRoute
+}
+
+// Deprecation is the deprecation of a version, it is announced by the Deprecation (RFC 9745),
+// Sunset (RFC 8594) and Link headers of the responses
+type Deprecation struct {
+	// At is the time the version is deprecated, Deprecation: true is sent if it is zero
+	At time.Time
+	// Sunset is the time the version becomes unavailable, optional
+	Sunset time.Time
+	// Link is the documentation of the deprecation, optional
+	Link string
+}
+
+type versionedRoute struct {
+	method string
+	// path is relative to the path of the version
+	path         string
+	handlers     []Handler
+	ginHandlers  gin.HandlersChain
+	middlewares  []Middleware
+	interceptors []Interceptor
+	timeout      *TimeoutConfig
+	guards       []guard
+}
+
+// Versioning create Versions under the path of the group
+func (r *RouterGroup) Versioning(config VersioningConfig) *Versions {
+	if config.Header == "" {
+		config.Header = defaultVersionHeader
+	}
+	if config.Param == "" {
+		config.Param = defaultVersionParam
+	}
+	vs := &Versions{group: r, config: config}
+	if r.engine != nil {
+		r.engine.versionings = append(r.engine.versionings, vs)
+	}
+	return vs
+}
+
+// Versioning create Versions under the root path, see RouterGroup.Versioning
+func (e *EasyGin) Versioning(config VersioningConfig) *Versions {
+	return e.root.Versioning(config)
+}
+
+// Version create the group of the version, versions must be created from the oldest to the latest,
+// name is the path segment of the version, etc.: v1, it matches the header or parameter 1 or v1
+func (vs *Versions) Version(name string, handlers ...Handler) *VersionGroup {
+	v := &VersionGroup{name: name}
+	v.RouterGroup = vs.group.Group(name, handlers...)
+	v.RouterGroup.version = v
+	vs.versions = append(vs.versions, v)
+	return v
+}
+
+// Deprecate mark the version as deprecated
+func (v *VersionGroup) Deprecate(d Deprecation) {
+	v.deprecation = &d
+}
+
+// record the route registered in the version or its sub groups
+func (v *VersionGroup) record(r *RouterGroup, httpMethod, fullPath string, handlers []Handler) {
+	v.routes = append(v.routes, versionedRoute{
+		method:       httpMethod,
+		path:         strings.TrimPrefix(fullPath, v.BasePath()),
+		handlers:     handlers,
+		ginHandlers:  append(gin.HandlersChain(nil), r.RouterGroup.Handlers...),
+		middlewares:  r.middlewares,
+		interceptors: r.interceptors,
+		timeout:      r.timeout,
+		guards:       r.guards,
+	})
+}
+
+// registerFallthrough register the routes of the earlier versions which are not registered in the newer versions
+func (vs *Versions) registerFallthrough() {
+	for j, v := range vs.versions {
+		registered := make(map[string]bool)
+		for _, rt := range v.routes {
+			registered[rt.method+" "+rt.path] = true
+		}
+		for i := j - 1; i >= 0; i-- {
+			for _, rt := range vs.versions[i].routes {
+				if key := rt.method + " " + rt.path; !registered[key] {
+					registered[key] = true
+					v.register(rt)
+				}
+			}
+		}
+	}
+}
+
+// register the route of an earlier version with its middlewares under the path of v
+func (v *VersionGroup) register(rt versionedRoute) {
+	group := v.engine.Engine.Group(v.BasePath())
+	group.Handlers = rt.ginHandlers
+	r := &RouterGroup{
+		RouterGroup:  group,
+		engine:       v.engine,
+		middlewares:  rt.middlewares,
+		interceptors: rt.interceptors,
+		timeout:      rt.timeout,
+		guards:       rt.guards,
+	}
+	r.handle(rt.method, rt.path, rt.handlers)
+}
+
+// route select the version of the request, the path is rewritten if the version is not in the path.
+// it returns false if the version is not supported and the response has been written
+func (vs *Versions) route(w http.ResponseWriter, req *http.Request) bool {
+	base := strings.TrimSuffix(vs.group.BasePath(), "/")
+	p := req.URL.Path
+	if !strings.HasPrefix(p, base+"/") {
+		return true
+	}
+	segment := p[len(base)+1:]
+	if i := strings.IndexByte(segment, '/'); i >= 0 {
+		segment = segment[:i]
+	}
+
+	v := vs.lookup(segment, true)
+	if v == nil {
+		if vs.config.Strategy == VersionByPath {
+			return true
+		}
+		requested := vs.requested(req)
+		if v = vs.lookup(requested, false); v == nil {
+			resp := FailStatus(http.StatusBadRequest, RespUnsupportedVersion)
+			writeJSON(w, resp)
+			return false
+		}
+		req.URL.Path = base + "/" + v.name + p[len(base):]
+		if req.URL.RawPath != "" {
+			req.URL.RawPath = base + "/" + v.name + req.URL.RawPath[len(base):]
+		}
+	}
+
+	switch vs.config.Strategy {
+	case VersionByHeader:
+		w.Header().Add("Vary", vs.config.Header)
+	case VersionByAccept:
+		w.Header().Add("Vary", "Accept")
+	}
+	if d := v.deprecation; d != nil {
+		if d.At.IsZero() {
+			w.Header().Set("Deprecation", "true")
+		} else {
+			w.Header().Set("Deprecation", "@"+strconv.FormatInt(d.At.Unix(), 10))
+		}
+		if !d.Sunset.IsZero() {
+			w.Header().Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if d.Link != "" {
+			w.Header().Add("Link", "<"+d.Link+`>; rel="deprecation"`)
+		}
+	}
+	return true
+}
+
+// requested return the version requested by the header or the Accept media type, or the default version
+func (vs *Versions) requested(req *http.Request) string {
+	switch vs.config.Strategy {
+	case VersionByHeader:
+		if version := req.Header.Get(vs.config.Header); version != "" {
+			return version
+		}
+	case VersionByAccept:
+		for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+			_, params, err := mime.ParseMediaType(accept)
+			if err == nil && params[vs.config.Param] != "" {
+				return params[vs.config.Param]
+			}
+		}
+	}
+	if vs.config.Default != "" {
+		return vs.config.Default
+	}
+	if len(vs.versions) > 0 {
+		return vs.versions[len(vs.versions)-1].name
+	}
+	return ""
+}
+
+// lookup find the version by name, the prefix v is optional if exact is false, etc.: 2 matches v2
+func (vs *Versions) lookup(name string, exact bool) *VersionGroup {
+	for _, v := range vs.versions {
+		if v.name == name {
+			return v
+		}
+		if !exact && strings.TrimPrefix(strings.ToLower(v.name), "v") == strings.TrimPrefix(strings.ToLower(name), "v") {
+			return v
+		}
+	}
+	return nil
+}
+
+// writeJSON write the response without gin, it is used before routing
+func writeJSON(w http.ResponseWriter, resp *Response) {
+	data, err := resp.R.MarshalJSON()
+	status := resp.Status
+	pool.Put(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,84 @@
+package easygin
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVersioning(t *testing.T) {
+	sunset := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	newServer := func(config VersioningConfig) *EasyGin {
+		server := New()
+		server.GET("/ping", func() *Response {
+			return Ok()
+		})
+		versions := server.Group("/api").Versioning(config)
+		v1 := versions.Version("v1")
+		v1.Deprecate(Deprecation{At: time.Unix(1700000000, 0), Sunset: sunset, Link: "https://example.com/v2"})
+		v2 := versions.Version("v2")
+		users := v1.Group("/users", func(ctx *gin.Context, next func() *Response) *Response {
+			return next().SetMeta("group", "users")
+		})
+		users.GET("/:id", func(ctx *gin.Context) *Response {
+			return OkData("v1 " + ctx.Param("id"))
+		})
+		v1.GET("/orders", func() *Response {
+			return OkData("v1 orders")
+		})
+		v2.GET("/orders", func() *Response {
+			return OkData("v2 orders")
+		})
+		return server
+	}
+
+	tests := []struct {
+		config      VersioningConfig
+		path        string
+		header      []string
+		status      int
+		body        string
+		deprecation string
+	}{
+		{VersioningConfig{}, "/api/v1/orders", nil, 200, `{"data":"v1 orders","code":0,"message":"success"}`, "@1700000000"},
+		{VersioningConfig{}, "/api/v2/orders", nil, 200, `{"data":"v2 orders","code":0,"message":"success"}`, ""},
+		{VersioningConfig{}, "/api/v2/users/3", nil, 200, `{"data":"v1 3","code":0,"message":"success","meta":{"group":"users"}}`, ""},
+		{VersioningConfig{}, "/api/orders", nil, 404, ``, ""},
+		{VersioningConfig{}, "/ping", nil, 200, `{"data":null,"code":0,"message":"success"}`, ""},
+		{VersioningConfig{Strategy: VersionByHeader}, "/api/orders", []string{"X-API-Version", "1"}, 200, `{"data":"v1 orders","code":0,"message":"success"}`, "@1700000000"},
+		{VersioningConfig{Strategy: VersionByHeader}, "/api/orders", nil, 200, `{"data":"v2 orders","code":0,"message":"success"}`, ""},
+		{VersioningConfig{Strategy: VersionByHeader, Default: "v1"}, "/api/orders", nil, 200, `{"data":"v1 orders","code":0,"message":"success"}`, "@1700000000"},
+		{VersioningConfig{Strategy: VersionByHeader}, "/api/orders", []string{"X-API-Version", "3"}, 400, `{"data":null,"code":400,"message":"unsupported api version"}`, ""},
+		{VersioningConfig{Strategy: VersionByAccept}, "/api/users/5", []string{"Accept", "application/json; version=2"}, 200, `{"data":"v1 5","code":0,"message":"success","meta":{"group":"users"}}`, ""},
+		{VersioningConfig{Strategy: VersionByAccept}, "/api/v1/orders", []string{"Accept", "application/json; version=2"}, 200, `{"data":"v1 orders","code":0,"message":"success"}`, "@1700000000"},
+	}
+	t.Run("fallthrough registered before serving", func(t *testing.T) {
+		server := newServer(VersioningConfig{})
+		found := false
+		for _, info := range server.RegisteredRoutes() {
+			found = found || info.Method == http.MethodGet && info.Path == "/api/v2/users/:id"
+		}
+		if !found {
+			t.Errorf("expect the fallthrough route in the registered routes: %+v", server.RegisteredRoutes())
+		}
+		// the Engine serves the fallthrough routes without EasyGin
+		if w := serveRequest(server.Engine, http.MethodGet, "/api/v2/users/3", nil); w.Code != http.StatusOK {
+			t.Errorf("expect the Engine to serve the fallthrough route, got %d", w.Code)
+		}
+	})
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v %s %v", test.config, test.path, test.header), func(t *testing.T) {
+			w := serveRequest(newServer(test.config), http.MethodGet, test.path, nil, test.header...)
+			if w.Code != test.status || (test.body != "" && w.Body.String() != test.body) || w.Header().Get("Deprecation") != test.deprecation {
+				t.Errorf("expect %d %s %q, got %d %s %q", test.status, test.body, test.deprecation, w.Code, w.Body.String(), w.Header().Get("Deprecation"))
+			}
+			if test.deprecation != "" && w.Header().Get("Sunset") != "Tue, 01 Jan 2030 00:00:00 GMT" {
+				t.Errorf("unexpected sunset %q", w.Header().Get("Sunset"))
+			}
+		})
+	}
+}